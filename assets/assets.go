@@ -0,0 +1,259 @@
+// Package assets loads shaders, textures, meshes and materials from an fs.FS
+// once and shares them.
+//
+// Assets are cached by kind, normalized path and load options, and handed out
+// as reference counted handles. Releasing the last handle of an asset doesn't
+// free it, UnloadUnused does, so assets released and requested again in
+// between are not reloaded. Concurrent requests for an asset being loaded
+// wait for that load instead of starting another.
+//
+// A Manager can be used from any goroutine. Files are read and decoded on the
+// calling goroutine and GL objects created and freed through window.Do.
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/example/material"
+	"github.com/go-gl/example/window"
+)
+
+// Kind is the type of an asset.
+type Kind string
+
+const (
+	Shaders  Kind = "shader"
+	Textures Kind = "texture"
+	Meshes   Kind = "mesh"
+	// Materials hold handles to their shader and textures.
+	Materials Kind = "material"
+)
+
+type Manager struct {
+	fsys fs.FS
+
+	mutex   sync.Mutex
+	entries map[key]*entry
+}
+
+type key struct {
+	kind Kind
+	name string
+	// variant tells apart loads of the same file with different options.
+	variant interface{}
+}
+
+// loader loads and frees one kind of asset, load runs on the requesting
+// goroutine and free on the main thread.
+type loader struct {
+	load func() (value interface{}, size int, err error)
+	free func(value interface{})
+}
+
+type entry struct {
+	key    key
+	loader loader
+	// ready is closed when the first load is over, value, size and err are
+	// set from then on.
+	ready chan struct{}
+	value interface{}
+	size  int
+	err   error
+	refs  int
+}
+
+// Usage is how much of one kind of asset is loaded.
+type Usage struct {
+	Count int
+	// Bytes estimates the GPU memory used, shaders count for nothing.
+	Bytes int
+}
+
+// Handle is a reference to a loaded asset.
+type Handle[T any] struct {
+	manager  *Manager
+	entry    *entry
+	released bool
+}
+
+// Get returns the asset, which changes when it's reloaded.
+func (handle *Handle[T]) Get() T {
+	handle.manager.mutex.Lock()
+	defer handle.manager.mutex.Unlock()
+
+	return handle.entry.value.(T)
+}
+
+// Name is the normalized path the asset was loaded from.
+func (handle *Handle[T]) Name() string {
+	return handle.entry.key.name
+}
+
+// Release drops the reference, the handle can't be used afterwards. Releasing
+// twice does nothing.
+func (handle *Handle[T]) Release() {
+	handle.manager.mutex.Lock()
+	defer handle.manager.mutex.Unlock()
+
+	if !handle.released {
+		handle.released = true
+		handle.entry.refs--
+	}
+}
+
+// acquire returns the cached entry for key with a new reference, loading it
+// first if needed.
+func (manager *Manager) acquire(key key, loader loader) (*entry, error) {
+	manager.mutex.Lock()
+	if cached, ok := manager.entries[key]; ok {
+		cached.refs++
+		manager.mutex.Unlock()
+
+		// The loading goroutine may be waiting on the main thread in
+		// window.Do, which might be this one
+		window.Wait(cached.ready)
+		if cached.err != nil {
+			return nil, cached.err
+		}
+		return cached, nil
+	}
+
+	loading := &entry{key: key, loader: loader, ready: make(chan struct{}), refs: 1}
+	manager.entries[key] = loading
+	manager.mutex.Unlock()
+
+	value, size, err := loader.load()
+
+	manager.mutex.Lock()
+	loading.value, loading.size, loading.err = value, size, err
+	if err != nil {
+		// Forget failures, the next request tries again
+		delete(manager.entries, key)
+	}
+	manager.mutex.Unlock()
+
+	close(loading.ready)
+
+	if err != nil {
+		return nil, err
+	}
+	return loading, nil
+}
+
+func newHandle[T any](manager *Manager, entry *entry, err error) (*Handle[T], error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handle[T]{manager: manager, entry: entry}, nil
+}
+
+// UnloadUnused frees the assets no handle references and returns how many
+// there were.
+func (manager *Manager) UnloadUnused() int {
+	var unused []*entry
+
+	manager.mutex.Lock()
+	for key, entry := range manager.entries {
+		if entry.refs == 0 {
+			unused = append(unused, entry)
+			delete(manager.entries, key)
+		}
+	}
+	manager.mutex.Unlock()
+
+	window.Do(func() {
+		for _, entry := range unused {
+			entry.loader.free(entry.value)
+		}
+		// GL reuses the names of the objects freed, materials mustn't
+		// take new ones for those they bound
+		if len(unused) > 0 {
+			material.Invalidate()
+		}
+	})
+
+	return len(unused)
+}
+
+// Reload loads every cached asset again, for files changed on disk. Handles
+// get the new assets and the old ones are freed. Assets failing to reload
+// keep their previous version, the errors are joined.
+func (manager *Manager) Reload() error {
+	manager.mutex.Lock()
+	var loaded []*entry
+	for _, entry := range manager.entries {
+		select {
+		case <-entry.ready:
+			loaded = append(loaded, entry)
+		default:
+			// Being loaded for the first time already
+		}
+	}
+	manager.mutex.Unlock()
+
+	// Materials last, they copy the shaders and textures reloaded before
+	sort.SliceStable(loaded, func(i, j int) bool {
+		return loaded[i].key.kind != Materials && loaded[j].key.kind == Materials
+	})
+
+	var errs []error
+	for _, entry := range loaded {
+		value, size, err := entry.loader.load()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		manager.mutex.Lock()
+		previous := entry.value
+		if manager.entries[entry.key] == entry {
+			entry.value, entry.size = value, size
+		} else {
+			// Unloaded meanwhile
+			previous = value
+		}
+		manager.mutex.Unlock()
+
+		window.Do(func() {
+			entry.loader.free(previous)
+			material.Invalidate()
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// Usage reports the assets loaded by kind.
+func (manager *Manager) Usage() map[Kind]Usage {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	usage := make(map[Kind]Usage)
+	for key, entry := range manager.entries {
+		select {
+		case <-entry.ready:
+		default:
+			continue
+		}
+
+		kind := usage[key.kind]
+		kind.Count++
+		kind.Bytes += entry.size
+		usage[key.kind] = kind
+	}
+
+	return usage
+}
+
+// normalize makes equivalent paths the same key: "./images/../a.png",
+// "a.png" and "/a.png" all become "a.png".
+func normalize(name string) string {
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
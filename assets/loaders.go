@@ -0,0 +1,227 @@
+package assets
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/example/gltf"
+	"github.com/go-gl/example/material"
+	"github.com/go-gl/example/mesh"
+	"github.com/go-gl/example/obj"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/example/window"
+)
+
+// NewManager creates a manager loading files from fsys, such as
+// os.DirFS("assets") or an embed.FS.
+func NewManager(fsys fs.FS) *Manager {
+	return &Manager{fsys: fsys, entries: make(map[key]*entry)}
+}
+
+// Texture loads an image file as texture.LoadFS does.
+func (manager *Manager) Texture(name string, options texture.Options) (*Handle[*texture.Texture], error) {
+	name = normalize(name)
+
+	entry, err := manager.acquire(key{Textures, name, options}, loader{
+		load: func() (interface{}, int, error) {
+			data, err := fs.ReadFile(manager.fsys, name)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			// Decode here, only the upload needs the main thread
+			prepared, err := texture.Prepare(name, data, options)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			var loaded *texture.Texture
+			window.Do(func() {
+				if loaded, err = prepared.Upload(); err == nil {
+					loaded.Label(name)
+				}
+			})
+			if err != nil {
+				return nil, 0, err
+			}
+
+			return loaded, loaded.Size(), nil
+		},
+		free: func(value interface{}) { value.(*texture.Texture).Delete() },
+	})
+
+	return newHandle[*texture.Texture](manager, entry, err)
+}
+
+// Shader builds a program from vertex and fragment shader source files.
+func (manager *Manager) Shader(vertex string, fragment string) (*Handle[shader.Shader], error) {
+	vertex, fragment = normalize(vertex), normalize(fragment)
+	name := vertex + " + " + fragment
+
+	entry, err := manager.acquire(key{Shaders, name, nil}, loader{
+		load: func() (interface{}, int, error) {
+			vertexSource, err := fs.ReadFile(manager.fsys, vertex)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			fragmentSource, err := fs.ReadFile(manager.fsys, fragment)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			var program shader.Shader
+			window.Do(func() {
+				program, err = shader.CreateFromSource(string(vertexSource), string(fragmentSource))
+				if err == nil {
+					program.Label(name)
+				}
+			})
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %v", name, err)
+			}
+
+			return program, 0, nil
+		},
+		free: func(value interface{}) { value.(shader.Shader).Delete() },
+	})
+
+	return newHandle[shader.Shader](manager, entry, err)
+}
+
+// Mesh loads a Wavefront OBJ or glTF model to a mesh. glTF files can hold
+// several meshes, the first one is loaded unless the name ends with
+// "#index", like "level.glb#3".
+func (manager *Manager) Mesh(name string) (*Handle[*mesh.Mesh], error) {
+	file, index := name, 0
+	if hash := strings.LastIndexByte(name, '#'); hash >= 0 {
+		number, err := strconv.Atoi(name[hash+1:])
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("%s: invalid mesh index", name)
+		}
+		file, index = name[:hash], number
+	}
+
+	file = normalize(file)
+	name = fmt.Sprintf("%s#%d", file, index)
+
+	entry, err := manager.acquire(key{Meshes, name, nil}, loader{
+		load: func() (interface{}, int, error) {
+			var loaded *mesh.Mesh
+
+			switch strings.ToLower(path.Ext(file)) {
+			case ".obj":
+				model, err := obj.Load(manager.fsys, file)
+				if err != nil {
+					return nil, 0, err
+				}
+				if index != 0 {
+					return nil, 0, fmt.Errorf("%s: OBJ files have a single mesh", name)
+				}
+
+				window.Do(func() { loaded = model.NewMesh() })
+
+			case ".gltf", ".glb":
+				model, err := gltf.Load(manager.fsys, file)
+				if err != nil {
+					return nil, 0, err
+				}
+				if index >= len(model.Meshes) {
+					return nil, 0, fmt.Errorf("%s: the file has %d meshes", name, len(model.Meshes))
+				}
+
+				window.Do(func() { loaded, err = model.NewMesh(index) })
+				if err != nil {
+					return nil, 0, fmt.Errorf("%s: %v", file, err)
+				}
+
+			default:
+				return nil, 0, fmt.Errorf("%s: unknown model format", file)
+			}
+
+			window.Do(func() { loaded.Label(name) })
+
+			return loaded, loaded.Size(), nil
+		},
+		free: func(value interface{}) { value.(*mesh.Mesh).Delete() },
+	})
+
+	return newHandle[*mesh.Mesh](manager, entry, err)
+}
+
+// Material loads a JSON material definition, see material.Definition, along
+// with its shader and textures, whose paths are relative to the definition's
+// directory. The material holds handles to them until it's unloaded, so they
+// are freed by the UnloadUnused call after the material's.
+func (manager *Manager) Material(name string) (*Handle[*material.Material], error) {
+	name = normalize(name)
+
+	// The dependencies of each loaded version, released when it's freed
+	var mutex sync.Mutex
+	releases := make(map[*material.Material]func())
+
+	entry, err := manager.acquire(key{Materials, name, nil}, loader{
+		load: func() (interface{}, int, error) {
+			file, err := manager.fsys.Open(name)
+			if err != nil {
+				return nil, 0, err
+			}
+			definition, err := material.ReadDefinition(file)
+			file.Close()
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %v", name, err)
+			}
+
+			var acquired []func()
+			release := func() {
+				for _, release := range acquired {
+					release()
+				}
+			}
+
+			directory := path.Dir(name)
+			program, err := manager.Shader(path.Join(directory, definition.Vertex), path.Join(directory, definition.Fragment))
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %v", name, err)
+			}
+			acquired = append(acquired, program.Release)
+
+			loaded := material.New(name, program.Get())
+			for uniform, value := range definition.Uniforms {
+				loaded.Uniforms[uniform] = value
+			}
+
+			for slot, slotDefinition := range definition.Textures {
+				slotTexture, err := manager.Texture(path.Join(directory, slotDefinition.Path), slotDefinition.Options())
+				if err != nil {
+					release()
+					return nil, 0, fmt.Errorf("%s: %v", name, err)
+				}
+				acquired = append(acquired, slotTexture.Release)
+
+				loaded.SetTexture(slot, slotTexture.Get())
+			}
+
+			mutex.Lock()
+			releases[loaded] = release
+			mutex.Unlock()
+
+			return loaded, 0, nil
+		},
+		free: func(value interface{}) {
+			mutex.Lock()
+			release := releases[value.(*material.Material)]
+			delete(releases, value.(*material.Material))
+			mutex.Unlock()
+
+			release()
+		},
+	})
+
+	return newHandle[*material.Material](manager, entry, err)
+}
@@ -0,0 +1,185 @@
+// Package buffer wraps GL buffer objects holding a slice of a single Go type.
+package buffer
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Buffer is a GL buffer object holding values of type T, for example vertex
+// structs on gl.ARRAY_BUFFER or uint32 indices on gl.ELEMENT_ARRAY_BUFFER.
+//
+// T has to be plain data, numbers and arrays or structs of them. Its memory is
+// copied to the GPU as is, and Map hands out GL memory the garbage collector
+// doesn't scan, so pointers, slices, strings, maps and interfaces can't work.
+type Buffer[T any] struct {
+	Id     uint32
+	Target uint32
+	// Usage hint passed to glBufferData, gl.STATIC_DRAW, gl.DYNAMIC_DRAW or
+	// gl.STREAM_DRAW.
+	Usage uint32
+
+	length int
+	mapped bool
+}
+
+// New creates an empty buffer, Upload gives it storage. It panics when T holds
+// pointers.
+func New[T any](target uint32, usage uint32) *Buffer[T] {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); hasPointers(t) {
+		panic(fmt.Sprintf("buffer: %v holds pointers, buffers store plain data", t))
+	}
+
+	buffer := &Buffer[T]{Target: target, Usage: usage}
+	gl.GenBuffers(1, &buffer.Id)
+
+	return buffer
+}
+
+// NewWithData creates a buffer and uploads data to it.
+func NewWithData[T any](target uint32, usage uint32, data []T) *Buffer[T] {
+	buffer := New[T](target, usage)
+	buffer.Upload(data)
+
+	return buffer
+}
+
+// ElementSize is the size of T in bytes, the stride between elements.
+func (buffer *Buffer[T]) ElementSize() int {
+	var zero T
+	return int(unsafe.Sizeof(zero))
+}
+
+// Len is the number of elements the buffer has storage for.
+func (buffer *Buffer[T]) Len() int {
+	return buffer.length
+}
+
+// Size is the buffer's storage in bytes.
+func (buffer *Buffer[T]) Size() int {
+	return buffer.length * buffer.ElementSize()
+}
+
+func (buffer *Buffer[T]) Bind() {
+	gl.BindBuffer(buffer.Target, buffer.Id)
+}
+
+func (buffer *Buffer[T]) Unbind() {
+	gl.BindBuffer(buffer.Target, 0)
+}
+
+// Label names the buffer in GL debug output.
+func (buffer *Buffer[T]) Label(name string) {
+	gldebug.Label(gl.BUFFER, buffer.Id, name)
+}
+
+// Upload replaces the buffer's storage with data, resizing it to len(data).
+func (buffer *Buffer[T]) Upload(data []T) {
+	buffer.Bind()
+
+	var ptr unsafe.Pointer
+	if len(data) > 0 {
+		ptr = gl.Ptr(&data[0])
+	}
+
+	gl.BufferData(buffer.Target, len(data)*buffer.ElementSize(), ptr, buffer.Usage)
+	buffer.length = len(data)
+
+	gldebug.Check("buffer.Upload")
+}
+
+// SubUpload overwrites part of the buffer starting at element offset without
+// reallocating it. The data has to fit in the current storage.
+func (buffer *Buffer[T]) SubUpload(offset int, data []T) error {
+	if offset < 0 || offset+len(data) > buffer.length {
+		return fmt.Errorf("buffer: sub upload of %d elements at %d out of range of %d", len(data), offset, buffer.length)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	buffer.Bind()
+	gl.BufferSubData(buffer.Target, offset*buffer.ElementSize(), len(data)*buffer.ElementSize(), gl.Ptr(&data[0]))
+
+	gldebug.Check("buffer.SubUpload")
+	return nil
+}
+
+// Orphan gives the buffer new storage of the same size, so the driver doesn't
+// stall waiting for draws still reading the old contents. Streamed data is
+// usually orphaned before being rewritten every frame.
+func (buffer *Buffer[T]) Orphan() {
+	buffer.Bind()
+	gl.BufferData(buffer.Target, buffer.Size(), nil, buffer.Usage)
+
+	gldebug.Check("buffer.Orphan")
+}
+
+// Map maps length elements starting at offset into memory, access is a
+// combination of the gl.MAP_*_BIT flags. The slice is only valid until Unmap.
+func (buffer *Buffer[T]) Map(offset int, length int, access uint32) ([]T, error) {
+	if buffer.mapped {
+		return nil, fmt.Errorf("buffer: already mapped")
+	}
+
+	if offset < 0 || length <= 0 || offset+length > buffer.length {
+		return nil, fmt.Errorf("buffer: map of %d elements at %d out of range of %d", length, offset, buffer.length)
+	}
+
+	buffer.Bind()
+	ptr := gl.MapBufferRange(buffer.Target, offset*buffer.ElementSize(), length*buffer.ElementSize(), access)
+	if ptr == nil {
+		gldebug.Check("buffer.Map")
+		return nil, fmt.Errorf("buffer: failed to map buffer %d", buffer.Id)
+	}
+
+	buffer.mapped = true
+
+	return unsafe.Slice((*T)(ptr), length), nil
+}
+
+// Unmap releases the range returned by Map. It returns false if the contents
+// got corrupted while mapped, they have to be uploaded again then.
+func (buffer *Buffer[T]) Unmap() bool {
+	if !buffer.mapped {
+		return true
+	}
+
+	buffer.Bind()
+	buffer.mapped = false
+
+	return gl.UnmapBuffer(buffer.Target)
+}
+
+func (buffer *Buffer[T]) Delete() {
+	gl.DeleteBuffers(1, &buffer.Id)
+	buffer.Id = 0
+	buffer.length = 0
+	// Deleting a buffer unmaps it
+	buffer.mapped = false
+}
+
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+// Package camera builds view and projection matrices and moves cameras from
+// user input.
+//
+// Cameras follow the window's aspect ratio until deleted. Controllers only do
+// math on an Input, which ReadInput fills from the window each frame:
+//
+//	view := camera.NewPerspective(mgl32.DegToRad(60), 0.1, 100)
+//	view.LookAt(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0})
+//	orbit := camera.NewOrbit(mgl32.Vec3{}, 1)
+//	orbit.LookFrom(view.Position)
+//
+//	// every frame
+//	orbit.Update(&view.Transform, camera.ReadInput(elapsed))
+package camera
+
+import (
+	"github.com/go-gl/example/window"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Camera is anything giving the matrices to draw from a point of view.
+type Camera interface {
+	View() mgl32.Mat4
+	Projection() mgl32.Mat4
+}
+
+// Transform places a camera in the world, looking down its local -Z axis with
+// +Y up.
+type Transform struct {
+	Position mgl32.Vec3
+	Rotation mgl32.Quat
+}
+
+// View is the matrix from world to camera space, the inverse of the camera's
+// transform.
+func (transform *Transform) View() mgl32.Mat4 {
+	return transform.Rotation.Inverse().Mat4().Mul4(mgl32.Translate3D(
+		-transform.Position.X(), -transform.Position.Y(), -transform.Position.Z()))
+}
+
+// LookAt moves the camera to eye and turns it towards target.
+func (transform *Transform) LookAt(eye mgl32.Vec3, target mgl32.Vec3, up mgl32.Vec3) {
+	transform.Position = eye
+	transform.Rotation = mgl32.Mat4ToQuat(mgl32.LookAtV(eye, target, up)).Inverse().Normalize()
+}
+
+// Forward is the direction the camera looks in.
+func (transform *Transform) Forward() mgl32.Vec3 {
+	return transform.Rotation.Rotate(mgl32.Vec3{0, 0, -1})
+}
+
+func (transform *Transform) Right() mgl32.Vec3 {
+	return transform.Rotation.Rotate(mgl32.Vec3{1, 0, 0})
+}
+
+func (transform *Transform) Up() mgl32.Vec3 {
+	return transform.Rotation.Rotate(mgl32.Vec3{0, 1, 0})
+}
+
+// Perspective is a camera seeing things smaller the further they are.
+type Perspective struct {
+	Transform
+
+	// FieldOfView is the vertical angle seen, in radians.
+	FieldOfView float32
+	// Aspect is the width divided by the height of the view.
+	Aspect    float32
+	Near, Far float32
+
+	removeResize func()
+}
+
+// NewPerspective creates a camera at the origin looking down -Z, whose aspect
+// ratio follows the window's framebuffer.
+func NewPerspective(fieldOfView float32, near float32, far float32) *Perspective {
+	camera := &Perspective{
+		Transform:   Transform{Rotation: mgl32.QuatIdent()},
+		FieldOfView: fieldOfView,
+		Aspect:      window.AspectRatio(),
+		Near:        near,
+		Far:         far,
+	}
+
+	camera.removeResize = window.OnFramebufferResize(func(int, int) {
+		camera.Aspect = window.AspectRatio()
+	})
+
+	return camera
+}
+
+func (camera *Perspective) Projection() mgl32.Mat4 {
+	return mgl32.Perspective(camera.FieldOfView, camera.Aspect, camera.Near, camera.Far)
+}
+
+// Delete stops following the window's aspect ratio.
+func (camera *Perspective) Delete() {
+	if camera.removeResize != nil {
+		camera.removeResize()
+		camera.removeResize = nil
+	}
+}
+
+// Orthographic is a camera seeing things the same size at any distance, for
+// 2D and technical views.
+type Orthographic struct {
+	Transform
+
+	// Height is how much of the world is seen vertically, the width follows
+	// from the aspect ratio. Making it smaller zooms in.
+	Height float32
+	// Aspect is the width divided by the height of the view.
+	Aspect    float32
+	Near, Far float32
+
+	removeResize func()
+}
+
+// NewOrthographic creates a camera at the origin looking down -Z and seeing
+// height world units vertically, whose aspect ratio follows the window's
+// framebuffer. For 2D, near and far can be -1 and 1.
+func NewOrthographic(height float32, near float32, far float32) *Orthographic {
+	camera := &Orthographic{
+		Transform: Transform{Rotation: mgl32.QuatIdent()},
+		Height:    height,
+		Aspect:    window.AspectRatio(),
+		Near:      near,
+		Far:       far,
+	}
+
+	camera.removeResize = window.OnFramebufferResize(func(int, int) {
+		camera.Aspect = window.AspectRatio()
+	})
+
+	return camera
+}
+
+// Width is how much of the world is seen horizontally.
+func (camera *Orthographic) Width() float32 {
+	return camera.Height * camera.Aspect
+}
+
+func (camera *Orthographic) Projection() mgl32.Mat4 {
+	halfWidth, halfHeight := camera.Width()/2, camera.Height/2
+	return mgl32.Ortho(-halfWidth, halfWidth, -halfHeight, halfHeight, camera.Near, camera.Far)
+}
+
+// Delete stops following the window's aspect ratio.
+func (camera *Orthographic) Delete() {
+	if camera.removeResize != nil {
+		camera.removeResize()
+		camera.removeResize = nil
+	}
+}
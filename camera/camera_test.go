@@ -0,0 +1,239 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const epsilon = 1e-4
+
+// near compares vectors component by component, mgl32's comparisons are
+// relative and fail next to 0.
+func near(a mgl32.Vec3, b mgl32.Vec3) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > epsilon {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestFlyFace(t *testing.T) {
+	for _, direction := range []mgl32.Vec3{
+		{0, 0, -1},
+		{1, 0, 0},
+		{0, 0, 1},
+		{-1, 2, 3},
+		{0.5, -1, -0.2},
+	} {
+		fly := NewFly()
+		fly.Face(direction)
+
+		transform := Transform{Rotation: mgl32.QuatIdent()}
+		fly.Update(&transform, Input{Elapsed: 1})
+
+		if got := transform.Forward(); !near(got, direction.Normalize()) {
+			t.Errorf("facing %v looks towards %v", direction, got)
+		}
+
+		if right := transform.Right(); math.Abs(float64(right.Y())) > epsilon {
+			t.Errorf("facing %v rolls, right is %v", direction, right)
+		}
+	}
+}
+
+func TestFlyMove(t *testing.T) {
+	fly := NewFly()
+	fly.Face(mgl32.Vec3{1, -1, 0})
+	fly.Planar = true
+
+	transform := Transform{Rotation: mgl32.QuatIdent()}
+	fly.Update(&transform, Input{Elapsed: 0.5, Move: mgl32.Vec3{0, 0, 1}})
+
+	// Forward on the horizontal plane whatever the pitch, Speed * Elapsed
+	if want := (mgl32.Vec3{2.5, 0, 0}); !near(transform.Position, want) {
+		t.Errorf("moved to %v, want %v", transform.Position, want)
+	}
+
+	transform.Position = mgl32.Vec3{}
+	fly.Update(&transform, Input{Elapsed: 1, Move: mgl32.Vec3{1, 0, 1}, Fast: true})
+
+	// Diagonals aren't faster
+	if length := transform.Position.Len(); math.Abs(float64(length-fly.Speed*fly.FastMultiplier)) > epsilon {
+		t.Errorf("moved %v, want %v", length, fly.Speed*fly.FastMultiplier)
+	}
+}
+
+func TestOrbitLookFrom(t *testing.T) {
+	target := mgl32.Vec3{1, 2, 3}
+
+	for _, position := range []mgl32.Vec3{
+		{1, 2, 8},
+		{4, 6, 3},
+		{-2, 0, -1},
+		{1.5, -3, 3.5},
+	} {
+		orbit := NewOrbit(target, 1)
+		orbit.LookFrom(position)
+
+		transform := Transform{Rotation: mgl32.QuatIdent()}
+		orbit.Update(&transform, Input{})
+
+		if !near(transform.Position, position) {
+			t.Errorf("looking from %v puts the camera at %v", position, transform.Position)
+		}
+
+		if forward, want := transform.Forward(), target.Sub(position).Normalize(); !near(forward, want) {
+			t.Errorf("looking from %v looks towards %v, want %v", position, forward, want)
+		}
+	}
+}
+
+func TestOrbitPitchClamp(t *testing.T) {
+	orbit := NewOrbit(mgl32.Vec3{}, 5)
+
+	transform := Transform{Rotation: mgl32.QuatIdent()}
+	orbit.Update(&transform, Input{Primary: true, CursorDelta: mgl32.Vec2{0, 10000}})
+
+	if orbit.Pitch != maxPitch {
+		t.Errorf("pitch %v, want %v", orbit.Pitch, float32(maxPitch))
+	}
+
+	if distance := transform.Position.Len(); math.Abs(float64(distance-5)) > epsilon {
+		t.Errorf("camera %v away from the target, want 5", distance)
+	}
+}
+
+// worldUnder returns the point of the plane shown under cursor.
+func worldUnder(camera *Orthographic, cursor mgl32.Vec2, viewport mgl32.Vec2) mgl32.Vec3 {
+	perPixel := camera.Height / viewport.Y()
+	return camera.Position.
+		Add(camera.Right().Mul((cursor.X() - viewport.X()/2) * perPixel)).
+		Add(camera.Up().Mul((viewport.Y()/2 - cursor.Y()) * perPixel))
+}
+
+func TestPanZoom(t *testing.T) {
+	viewport := mgl32.Vec2{800, 600}
+
+	for _, test := range []struct {
+		cursor mgl32.Vec2
+		scroll float32
+	}{
+		{mgl32.Vec2{400, 300}, 1},
+		{mgl32.Vec2{100, 50}, 2},
+		{mgl32.Vec2{700, 550}, -3},
+	} {
+		camera := &Orthographic{
+			Transform: Transform{Position: mgl32.Vec3{5, -2, 0}, Rotation: mgl32.QuatIdent()},
+			Height:    10,
+			Aspect:    viewport.X() / viewport.Y(),
+		}
+		before := worldUnder(camera, test.cursor, viewport)
+
+		NewPanZoom().Update(camera, Input{Cursor: test.cursor, Viewport: viewport, Scroll: test.scroll})
+
+		if want := 10 * float32(math.Pow(1.1, float64(-test.scroll))); math.Abs(float64(camera.Height-want)) > epsilon {
+			t.Errorf("scrolling %v makes the height %v, want %v", test.scroll, camera.Height, want)
+		}
+
+		if after := worldUnder(camera, test.cursor, viewport); !near(after, before) {
+			t.Errorf("zooming at %v moved the point under the cursor from %v to %v", test.cursor, before, after)
+		}
+	}
+}
+
+func TestPanZoomDrag(t *testing.T) {
+	viewport := mgl32.Vec2{800, 600}
+	camera := &Orthographic{Transform: Transform{Rotation: mgl32.QuatIdent()}, Height: 6}
+
+	// Dragging right by a sixth of the height moves the view left by as much
+	NewPanZoom().Update(camera, Input{Primary: true, CursorDelta: mgl32.Vec2{100, 0}, Viewport: viewport})
+
+	if want := (mgl32.Vec3{-1, 0, 0}); !near(camera.Position, want) {
+		t.Errorf("camera at %v, want %v", camera.Position, want)
+	}
+}
+
+func TestZoom(t *testing.T) {
+	tests := []struct {
+		name                               string
+		distance, scroll, minimum, maximum float32
+		want                               float32
+	}{
+		{"no scroll", 5, 0, 1, 10, 5},
+		{"in", 5, 1, 1, 10, 5 / 1.1},
+		{"out", 5, -2, 1, 10, 5 * 1.1 * 1.1},
+		{"clamped in", 5, 100, 1, 10, 1},
+		{"clamped out", 5, -100, 1, 10, 10},
+		{"unbounded", 5, -10, 1, float32(math.Inf(1)), 5 * float32(math.Pow(1.1, 10))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := zoom(test.distance, test.scroll, 0.1, test.minimum, test.maximum)
+			if math.Abs(float64(got-test.want)) > epsilon {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestArcballPoint(t *testing.T) {
+	viewport := mgl32.Vec2{800, 600}
+
+	tests := []struct {
+		name   string
+		cursor mgl32.Vec2
+		want   mgl32.Vec3
+	}{
+		{"center", mgl32.Vec2{400, 300}, mgl32.Vec3{0, 0, 1}},
+		{"top of the ball", mgl32.Vec2{400, 0}, mgl32.Vec3{0, 1, 0}},
+		{"right of the ball", mgl32.Vec2{700, 300}, mgl32.Vec3{1, 0, 0}},
+		{"inside", mgl32.Vec2{475, 225}, mgl32.Vec3{0.25, 0.25, float32(math.Sqrt(1 - 2*0.25*0.25))}},
+		{"outside right", mgl32.Vec2{800, 300}, mgl32.Vec3{1, 0, 0}},
+		{"outside corner", mgl32.Vec2{800, 600}, mgl32.Vec3{0.8, -0.6, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := arcballPoint(test.cursor, viewport)
+			if !near(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+
+			if length := got.Len(); math.Abs(float64(length-1)) > epsilon {
+				t.Errorf("%v isn't on the unit ball", got)
+			}
+		})
+	}
+
+	if got := arcballPoint(mgl32.Vec2{10, 10}, mgl32.Vec2{}); got != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("empty viewport maps to %v", got)
+	}
+}
+
+func TestArcballDrag(t *testing.T) {
+	arcball := NewArcball(mgl32.Vec3{}, 4)
+	viewport := mgl32.Vec2{600, 600}
+
+	// Dragging from the center to the right rim turns the camera a quarter
+	// around the target, to its left
+	transform := Transform{Rotation: mgl32.QuatIdent()}
+	arcball.Update(&transform, Input{
+		Primary:     true,
+		Cursor:      mgl32.Vec2{600, 300},
+		CursorDelta: mgl32.Vec2{300, 0},
+		Viewport:    viewport,
+	})
+
+	if want := (mgl32.Vec3{-4, 0, 0}); !near(transform.Position, want) {
+		t.Errorf("camera at %v, want %v", transform.Position, want)
+	}
+
+	if forward := transform.Forward(); !near(forward, mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("camera looks towards %v", forward)
+	}
+}
@@ -0,0 +1,258 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// maxPitch keeps cameras from looking straight up or down, where yaw turns
+// the view around itself.
+const maxPitch = math.Pi/2 - 0.01
+
+// Fly moves a camera freely with the keyboard and turns it with the mouse,
+// while the cursor is captured or the secondary button held.
+type Fly struct {
+	// Speed is in world units per second, multiplied by FastMultiplier when
+	// going fast.
+	Speed          float32
+	FastMultiplier float32
+	// Sensitivity is the angle turned per screen coordinate, in radians.
+	Sensitivity float32
+	// Planar moves on the horizontal plane whatever the pitch, as walking
+	// does in first person games, up and down moving along world Y.
+	Planar bool
+
+	// Yaw turns left around world Y from looking down -Z, Pitch up.
+	Yaw, Pitch float32
+}
+
+func NewFly() *Fly {
+	return &Fly{Speed: 5, FastMultiplier: 4, Sensitivity: 0.003}
+}
+
+// Face points the controller towards direction.
+func (fly *Fly) Face(direction mgl32.Vec3) {
+	direction = direction.Normalize()
+	fly.Pitch = float32(math.Asin(float64(mgl32.Clamp(direction.Y(), -1, 1))))
+	fly.Yaw = float32(math.Atan2(float64(-direction.X()), float64(-direction.Z())))
+}
+
+func (fly *Fly) Update(transform *Transform, input Input) {
+	if input.Captured || input.Secondary {
+		fly.Yaw -= input.CursorDelta.X() * fly.Sensitivity
+		fly.Pitch -= input.CursorDelta.Y() * fly.Sensitivity
+		fly.Pitch = mgl32.Clamp(fly.Pitch, -maxPitch, maxPitch)
+	}
+
+	yaw := mgl32.QuatRotate(fly.Yaw, mgl32.Vec3{0, 1, 0})
+	transform.Rotation = yaw.Mul(mgl32.QuatRotate(fly.Pitch, mgl32.Vec3{1, 0, 0}))
+
+	speed := fly.Speed * input.Elapsed
+	if input.Fast {
+		speed *= fly.FastMultiplier
+	}
+
+	var movement mgl32.Vec3
+	if fly.Planar {
+		movement = yaw.Rotate(mgl32.Vec3{input.Move.X(), 0, -input.Move.Z()})
+		movement[1] = input.Move.Y()
+	} else {
+		movement = transform.Rotation.Rotate(mgl32.Vec3{input.Move.X(), input.Move.Y(), -input.Move.Z()})
+	}
+
+	// Diagonals aren't faster
+	if length := movement.Len(); length > 1 {
+		movement = movement.Mul(1 / length)
+	}
+
+	transform.Position = transform.Position.Add(movement.Mul(speed))
+}
+
+// Orbit turns a camera around a target like a turntable, keeping the horizon
+// level. Dragging with the primary button turns, with the secondary or middle
+// one pans, and scrolling zooms.
+type Orbit struct {
+	Target   mgl32.Vec3
+	Distance float32
+	// Yaw turns the camera around world Y, starting on +Z of the target,
+	// Pitch raises it above the target.
+	Yaw, Pitch float32
+
+	Sensitivity float32
+	// ZoomSpeed is the fraction of the distance one scroll step covers.
+	ZoomSpeed                float32
+	MinDistance, MaxDistance float32
+	// PanSpeed scales panning, at 1 the target moves about as fast as the
+	// cursor with a 53 degrees field of view.
+	PanSpeed float32
+}
+
+func NewOrbit(target mgl32.Vec3, distance float32) *Orbit {
+	return &Orbit{
+		Target:      target,
+		Distance:    distance,
+		Sensitivity: 0.005,
+		ZoomSpeed:   0.1,
+		MinDistance: 0.01,
+		MaxDistance: float32(math.Inf(1)),
+		PanSpeed:    1,
+	}
+}
+
+// LookFrom sets the distance and angles putting the camera at position.
+func (orbit *Orbit) LookFrom(position mgl32.Vec3) {
+	offset := position.Sub(orbit.Target)
+	orbit.Distance = offset.Len()
+	if orbit.Distance == 0 {
+		return
+	}
+
+	orbit.Pitch = float32(math.Asin(float64(offset.Y() / orbit.Distance)))
+	orbit.Yaw = float32(math.Atan2(float64(offset.X()), float64(offset.Z())))
+}
+
+func (orbit *Orbit) Update(transform *Transform, input Input) {
+	if input.Primary {
+		orbit.Yaw -= input.CursorDelta.X() * orbit.Sensitivity
+		orbit.Pitch += input.CursorDelta.Y() * orbit.Sensitivity
+		orbit.Pitch = mgl32.Clamp(orbit.Pitch, -maxPitch, maxPitch)
+	}
+
+	orbit.Distance = zoom(orbit.Distance, input.Scroll, orbit.ZoomSpeed, orbit.MinDistance, orbit.MaxDistance)
+
+	transform.Rotation = mgl32.QuatRotate(orbit.Yaw, mgl32.Vec3{0, 1, 0}).
+		Mul(mgl32.QuatRotate(-orbit.Pitch, mgl32.Vec3{1, 0, 0}))
+
+	if input.Secondary || input.Middle {
+		orbit.Target = orbit.Target.Add(pan(transform, input, orbit.Distance*orbit.PanSpeed))
+	}
+
+	transform.Position = orbit.Target.Add(transform.Rotation.Rotate(mgl32.Vec3{0, 0, orbit.Distance}))
+}
+
+// Arcball turns a camera around a target freely, as if dragging a ball
+// standing in front of it. Panning and zooming work as for Orbit.
+type Arcball struct {
+	Target   mgl32.Vec3
+	Distance float32
+	// Rotation is the camera's orientation, its position is Distance behind
+	// the target along it.
+	Rotation mgl32.Quat
+
+	ZoomSpeed                float32
+	MinDistance, MaxDistance float32
+	PanSpeed                 float32
+}
+
+func NewArcball(target mgl32.Vec3, distance float32) *Arcball {
+	return &Arcball{
+		Target:      target,
+		Distance:    distance,
+		Rotation:    mgl32.QuatIdent(),
+		ZoomSpeed:   0.1,
+		MinDistance: 0.01,
+		MaxDistance: float32(math.Inf(1)),
+		PanSpeed:    1,
+	}
+}
+
+func (arcball *Arcball) Update(transform *Transform, input Input) {
+	if input.Primary && input.CursorDelta != (mgl32.Vec2{}) {
+		from := arcballPoint(input.Cursor.Sub(input.CursorDelta), input.Viewport)
+		to := arcballPoint(input.Cursor, input.Viewport)
+
+		// The ball turns from from to to in camera space, the camera the
+		// other way around it
+		turn := mgl32.QuatBetweenVectors(from, to)
+		arcball.Rotation = arcball.Rotation.Mul(turn.Inverse()).Normalize()
+	}
+
+	arcball.Distance = zoom(arcball.Distance, input.Scroll, arcball.ZoomSpeed, arcball.MinDistance, arcball.MaxDistance)
+
+	transform.Rotation = arcball.Rotation
+
+	if input.Secondary || input.Middle {
+		arcball.Target = arcball.Target.Add(pan(transform, input, arcball.Distance*arcball.PanSpeed))
+	}
+
+	transform.Position = arcball.Target.Add(transform.Rotation.Rotate(mgl32.Vec3{0, 0, arcball.Distance}))
+}
+
+// arcballPoint maps a cursor position to the unit ball filling the smaller
+// side of the viewport, points outside it to its rim.
+func arcballPoint(cursor mgl32.Vec2, viewport mgl32.Vec2) mgl32.Vec3 {
+	size := min(viewport.X(), viewport.Y())
+	if size <= 0 {
+		return mgl32.Vec3{0, 0, 1}
+	}
+
+	x := (2*cursor.X() - viewport.X()) / size
+	y := (viewport.Y() - 2*cursor.Y()) / size
+
+	squared := x*x + y*y
+	if squared > 1 {
+		length := float32(math.Sqrt(float64(squared)))
+		return mgl32.Vec3{x / length, y / length, 0}
+	}
+
+	return mgl32.Vec3{x, y, float32(math.Sqrt(float64(1 - squared)))}
+}
+
+// zoom scales distance by scroll steps of speed, clamped.
+func zoom(distance float32, scroll float32, speed float32, minimum float32, maximum float32) float32 {
+	if scroll == 0 {
+		return distance
+	}
+
+	distance *= float32(math.Pow(float64(1+speed), float64(-scroll)))
+	return mgl32.Clamp(distance, minimum, maximum)
+}
+
+// pan is the world movement dragging the cursor makes in the camera's plane,
+// scale being the world distance the viewport height spans.
+func pan(transform *Transform, input Input, scale float32) mgl32.Vec3 {
+	if input.Viewport.Y() <= 0 {
+		return mgl32.Vec3{}
+	}
+
+	perPixel := scale / input.Viewport.Y()
+	right := transform.Right().Mul(-input.CursorDelta.X() * perPixel)
+	up := transform.Up().Mul(input.CursorDelta.Y() * perPixel)
+
+	return right.Add(up)
+}
+
+// PanZoom moves an orthographic camera over a 2D plane: dragging with any
+// button pans and scrolling zooms around the cursor.
+type PanZoom struct {
+	// ZoomSpeed is the fraction of the view height one scroll step zooms.
+	ZoomSpeed            float32
+	MinHeight, MaxHeight float32
+}
+
+func NewPanZoom() *PanZoom {
+	return &PanZoom{ZoomSpeed: 0.1, MinHeight: 0.001, MaxHeight: float32(math.Inf(1))}
+}
+
+func (panZoom *PanZoom) Update(camera *Orthographic, input Input) {
+	if input.Viewport.Y() <= 0 {
+		return
+	}
+
+	perPixel := camera.Height / input.Viewport.Y()
+	if input.Primary || input.Secondary || input.Middle {
+		camera.Position = camera.Position.Add(pan(&camera.Transform, input, camera.Height))
+	}
+
+	height := zoom(camera.Height, input.Scroll, panZoom.ZoomSpeed, panZoom.MinHeight, panZoom.MaxHeight)
+	if height == camera.Height {
+		return
+	}
+
+	// Keep the point under the cursor in place
+	cursor := camera.Right().Mul((input.Cursor.X() - input.Viewport.X()/2) * perPixel).
+		Add(camera.Up().Mul((input.Viewport.Y()/2 - input.Cursor.Y()) * perPixel))
+	camera.Position = camera.Position.Add(cursor.Mul(1 - height/camera.Height))
+	camera.Height = height
+}
@@ -0,0 +1,78 @@
+package camera
+
+import (
+	"github.com/go-gl/example/window"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Input is the state controllers react to for one frame. ReadInput fills it
+// from the window, tests and replays can build it directly.
+type Input struct {
+	// Elapsed is the frame's duration in seconds.
+	Elapsed float32
+
+	// Move is the keyboard movement asked for, X right, Y up and Z forward,
+	// each from -1 to 1.
+	Move mgl32.Vec3
+	Fast bool
+
+	// Cursor is the cursor position and CursorDelta its movement since the
+	// previous frame, in screen coordinates with Y going down.
+	Cursor      mgl32.Vec2
+	CursorDelta mgl32.Vec2
+	// Viewport is the window size in screen coordinates.
+	Viewport mgl32.Vec2
+	// Captured is set when the cursor is hidden and locked to the window,
+	// its movement then always turns a fly camera.
+	Captured bool
+
+	Primary, Secondary, Middle bool
+	// Scroll is how many steps the wheel turned, positive away from the user.
+	Scroll float32
+}
+
+// ReadInput reads the window's keyboard and mouse: WASD moves, E and Space go
+// up, Q and Control down, Shift goes fast.
+func ReadInput(elapsed float32) Input {
+	input := Input{
+		Elapsed:   elapsed,
+		Fast:      window.KeyDown(glfw.KeyLeftShift) || window.KeyDown(glfw.KeyRightShift),
+		Captured:  window.CursorCaptured(),
+		Primary:   window.MouseButtonDown(glfw.MouseButtonLeft),
+		Secondary: window.MouseButtonDown(glfw.MouseButtonRight),
+		Middle:    window.MouseButtonDown(glfw.MouseButtonMiddle),
+	}
+
+	input.Move = mgl32.Vec3{
+		axis(glfw.KeyD, glfw.KeyA),
+		axis(glfw.KeyE, glfw.KeyQ) + axis(glfw.KeySpace, glfw.KeyLeftControl),
+		axis(glfw.KeyW, glfw.KeyS),
+	}
+	input.Move[1] = mgl32.Clamp(input.Move[1], -1, 1)
+
+	x, y := window.Cursor()
+	input.Cursor = mgl32.Vec2{float32(x), float32(y)}
+	x, y = window.CursorDelta()
+	input.CursorDelta = mgl32.Vec2{float32(x), float32(y)}
+
+	width, height := window.Size()
+	input.Viewport = mgl32.Vec2{float32(width), float32(height)}
+
+	_, scroll := window.Scroll()
+	input.Scroll = float32(scroll)
+
+	return input
+}
+
+// axis is 1 when positive is held, -1 when negative is, 0 for both or none.
+func axis(positive glfw.Key, negative glfw.Key) float32 {
+	var value float32
+	if window.KeyDown(positive) {
+		value++
+	}
+	if window.KeyDown(negative) {
+		value--
+	}
+	return value
+}
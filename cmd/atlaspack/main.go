@@ -0,0 +1,70 @@
+// Command atlaspack packs the images of a directory into texture atlas pages
+// and a JSON file describing them, see the texture/atlas package.
+//
+//	atlaspack [-o dir] [-name atlas] [-max 2048] [-padding 2] [-extrude 1] images/
+package main
+
+import (
+	"flag"
+	"fmt"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"os"
+
+	"github.com/go-gl/example/texture/atlas"
+	_ "golang.org/x/image/bmp"
+)
+
+func main() {
+	output := flag.String("o", ".", "output directory")
+	name := flag.String("name", "atlas", "name of the JSON file and pages")
+	maxSize := flag.Int("max", 2048, "maximum page width and height")
+	padding := flag.Int("padding", 2, "transparent pixels between images")
+	extrusion := flag.Int("extrude", 1, "pixels of each image's edges repeated outwards")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: atlaspack [flags] directory\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	log.SetFlags(0)
+	log.SetPrefix("atlaspack: ")
+
+	sprites, err := atlas.LoadSprites(os.DirFS(flag.Arg(0)), ".")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if len(sprites) == 0 {
+		log.Fatalln("no images in", flag.Arg(0))
+	}
+
+	packed, err := atlas.Pack(sprites, atlas.Options{
+		MaxWidth:  *maxSize,
+		MaxHeight: *maxSize,
+		Padding:   *padding,
+		Extrude:   *extrusion,
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := os.MkdirAll(*output, 0o755); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := packed.Save(*output, *name); err != nil {
+		log.Fatalln(err)
+	}
+
+	for _, page := range packed.Metadata(*name).Pages {
+		fmt.Printf("%s %dx%d\n", page.File, page.Width, page.Height)
+	}
+	fmt.Printf("%d images on %d pages\n", len(sprites), len(packed.Pages))
+}
@@ -0,0 +1,395 @@
+// Package framebuffer renders to textures through framebuffer objects.
+package framebuffer
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/material"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/example/window"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+type Options struct {
+	// Size in pixels, ignored when WindowScale is set.
+	Width  int
+	Height int
+	// WindowScale sizes the framebuffer to the window's framebuffer times
+	// the scale, 0.5 rendering at half resolution, and resizes it with the
+	// window.
+	WindowScale float32
+
+	// Color has the internal format of each color attachment, in
+	// attachment order, like gl.RGBA8 or gl.RGBA16F for HDR. Formats must
+	// be normalized or floating point. None makes a depth only framebuffer.
+	Color []int32
+	// Filter is used to sample the color textures, gl.LINEAR by default.
+	Filter int32
+
+	// Depth is the internal format of the depth attachment, 0 for none:
+	// gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT32F, or gl.DEPTH24_STENCIL8
+	// and gl.DEPTH32F_STENCIL8 with a stencil buffer.
+	Depth int32
+	// DepthTexture stores depth in a texture that can be sampled, for shadow
+	// maps or effects reading depth, instead of a renderbuffer.
+	DepthTexture bool
+
+	// Samples above 1 enable multisampling. Drawing goes to multisampled
+	// renderbuffers, Resolve copies them to the textures.
+	Samples int
+}
+
+type Framebuffer struct {
+	Id      uint32
+	Width   int
+	Height  int
+	Samples int
+
+	// Color holds a texture per color attachment. With multisampling they
+	// hold the resolved images.
+	Color []*texture.Texture
+	// Depth is the depth texture with Options.DepthTexture, nil otherwise.
+	Depth *texture.Texture
+
+	options       Options
+	renderbuffers []uint32
+	// resolve is the single sampled framebuffer of multisampled ones.
+	resolve      *Framebuffer
+	removeResize func()
+	// resizeErr is the error of the last resize following the window.
+	resizeErr error
+}
+
+// New creates a framebuffer and checks it's complete.
+func New(options Options) (*Framebuffer, error) {
+	width, height := options.Width, options.Height
+	if options.WindowScale > 0 {
+		width, height = scaledWindowSize(options.WindowScale)
+	}
+
+	if options.Samples > 1 {
+		var maxSamples int32
+		gl.GetIntegerv(gl.MAX_SAMPLES, &maxSamples)
+		options.Samples = min(options.Samples, int(maxSamples))
+	}
+
+	framebuffer := &Framebuffer{options: options}
+
+	if options.Samples > 1 {
+		resolveOptions := options
+		resolveOptions.WindowScale, resolveOptions.Samples = 0, 0
+
+		framebuffer.resolve = &Framebuffer{options: resolveOptions}
+		framebuffer.resolve.allocateTextures()
+		framebuffer.Color, framebuffer.Depth = framebuffer.resolve.Color, framebuffer.resolve.Depth
+	} else {
+		framebuffer.allocateTextures()
+	}
+
+	if err := framebuffer.Resize(width, height); err != nil {
+		framebuffer.Delete()
+		return nil, err
+	}
+
+	if options.WindowScale > 0 {
+		framebuffer.removeResize = window.OnFramebufferResize(func(int, int) {
+			width, height := scaledWindowSize(options.WindowScale)
+			framebuffer.resizeErr = framebuffer.Resize(width, height)
+			if framebuffer.resizeErr != nil {
+				slog.Error("framebuffer: failed to follow the window size", "error", framebuffer.resizeErr)
+			}
+		})
+	}
+
+	return framebuffer, nil
+}
+
+// Err is the error of the last resize made to follow the window with
+// Options.WindowScale, nil when it succeeded. The framebuffer is incomplete
+// until a later resize succeeds, drawing to it does nothing.
+func (framebuffer *Framebuffer) Err() error {
+	return framebuffer.resizeErr
+}
+
+func scaledWindowSize(scale float32) (int, int) {
+	width, height := window.FramebufferSize()
+	return max(int(float32(width)*scale), 1), max(int(float32(height)*scale), 1)
+}
+
+// allocateTextures creates the texture structs, their GL objects are created
+// by Resize so the pointers stay valid across resizes.
+func (framebuffer *Framebuffer) allocateTextures() {
+	for _, format := range framebuffer.options.Color {
+		framebuffer.Color = append(framebuffer.Color, &texture.Texture{Target: gl.TEXTURE_2D, Depth: 1, Format: format})
+	}
+
+	if framebuffer.options.Depth != 0 && framebuffer.options.DepthTexture {
+		framebuffer.Depth = &texture.Texture{Target: gl.TEXTURE_2D, Depth: 1, Format: framebuffer.options.Depth}
+	}
+}
+
+// Resize recreates the attachments at a new size, their contents are lost.
+// Texture pointers stay the same, their Id changes.
+func (framebuffer *Framebuffer) Resize(width int, height int) error {
+	if width < 1 || height < 1 {
+		return fmt.Errorf("framebuffer: invalid size %dx%d", width, height)
+	}
+
+	if framebuffer.resolve != nil {
+		if err := framebuffer.resolve.Resize(width, height); err != nil {
+			return err
+		}
+	}
+
+	framebuffer.deleteAttachments()
+	framebuffer.Width, framebuffer.Height = width, height
+	framebuffer.Samples = max(framebuffer.options.Samples, 0)
+
+	if framebuffer.Id == 0 {
+		gl.GenFramebuffers(1, &framebuffer.Id)
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer.Id)
+
+	options := framebuffer.options
+	multisampled := framebuffer.Samples > 1
+
+	drawBuffers := make([]uint32, len(options.Color))
+	for i, format := range options.Color {
+		attachment := gl.COLOR_ATTACHMENT0 + uint32(i)
+		drawBuffers[i] = attachment
+
+		if multisampled {
+			framebuffer.attachRenderbuffer(attachment, format)
+		} else {
+			framebuffer.attachTexture(attachment, framebuffer.Color[i], gl.RGBA, gl.FLOAT)
+		}
+	}
+
+	if options.Depth != 0 {
+		attachment, format, dataType := uint32(gl.DEPTH_ATTACHMENT), uint32(gl.DEPTH_COMPONENT), uint32(gl.FLOAT)
+		if hasStencil(options.Depth) {
+			attachment, format, dataType = gl.DEPTH_STENCIL_ATTACHMENT, gl.DEPTH_STENCIL, gl.UNSIGNED_INT_24_8
+			if options.Depth == gl.DEPTH32F_STENCIL8 {
+				dataType = gl.FLOAT_32_UNSIGNED_INT_24_8_REV
+			}
+		}
+
+		if multisampled || !options.DepthTexture {
+			framebuffer.attachRenderbuffer(attachment, options.Depth)
+		} else {
+			framebuffer.attachTexture(attachment, framebuffer.Depth, format, dataType)
+		}
+	}
+
+	if len(drawBuffers) > 0 {
+		gl.DrawBuffers(int32(len(drawBuffers)), &drawBuffers[0])
+	} else {
+		gl.DrawBuffer(gl.NONE)
+		gl.ReadBuffer(gl.NONE)
+	}
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gldebug.Check("framebuffer.Resize")
+
+	// The textures were bound to be created, and may have the names of the
+	// deleted ones materials still think are bound
+	material.Invalidate()
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("framebuffer: incomplete, %s", statusDescription(status))
+	}
+
+	return nil
+}
+
+func (framebuffer *Framebuffer) attachTexture(attachment uint32, target *texture.Texture, format uint32, dataType uint32) {
+	target.Width, target.Height = framebuffer.Width, framebuffer.Height
+
+	gl.GenTextures(1, &target.Id)
+	gl.BindTexture(gl.TEXTURE_2D, target.Id)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, target.Format, int32(target.Width), int32(target.Height), 0, format, dataType, nil)
+
+	filter := orDefault(framebuffer.options.Filter, gl.LINEAR)
+	if format != gl.RGBA {
+		// Depth is read as is, not blended
+		filter = gl.NEAREST
+	}
+	target.SetOptions(texture.Options{MinFilter: filter, MagFilter: filter})
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, attachment, gl.TEXTURE_2D, target.Id, 0)
+}
+
+func (framebuffer *Framebuffer) attachRenderbuffer(attachment uint32, format int32) {
+	var renderbuffer uint32
+	gl.GenRenderbuffers(1, &renderbuffer)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, renderbuffer)
+
+	if framebuffer.Samples > 1 {
+		gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, int32(framebuffer.Samples), uint32(format), int32(framebuffer.Width), int32(framebuffer.Height))
+	} else {
+		gl.RenderbufferStorage(gl.RENDERBUFFER, uint32(format), int32(framebuffer.Width), int32(framebuffer.Height))
+	}
+
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, attachment, gl.RENDERBUFFER, renderbuffer)
+
+	framebuffer.renderbuffers = append(framebuffer.renderbuffers, renderbuffer)
+}
+
+func orDefault(value int32, fallback int32) int32 {
+	if value == 0 {
+		return fallback
+	}
+
+	return value
+}
+
+func hasStencil(format int32) bool {
+	return format == gl.DEPTH24_STENCIL8 || format == gl.DEPTH32F_STENCIL8
+}
+
+func statusDescription(status uint32) string {
+	switch status {
+	case gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:
+		return "an attachment can't be rendered to, check its format"
+	case gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:
+		return "there are no attachments"
+	case gl.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:
+		return "a draw buffer has no attachment"
+	case gl.FRAMEBUFFER_INCOMPLETE_READ_BUFFER:
+		return "the read buffer has no attachment"
+	case gl.FRAMEBUFFER_UNSUPPORTED:
+		return "the driver doesn't support this combination of formats"
+	case gl.FRAMEBUFFER_INCOMPLETE_MULTISAMPLE:
+		return "attachments have different sample counts"
+	case gl.FRAMEBUFFER_INCOMPLETE_LAYER_TARGETS_ARB:
+		return "attachments aren't all layered"
+	case gl.FRAMEBUFFER_UNDEFINED:
+		return "no framebuffer is bound"
+	}
+
+	return fmt.Sprintf("status 0x%x", status)
+}
+
+// Bind makes drawing go to the framebuffer and sets the viewport to its size.
+func (framebuffer *Framebuffer) Bind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer.Id)
+	gl.Viewport(0, 0, int32(framebuffer.Width), int32(framebuffer.Height))
+}
+
+// BindDefault makes drawing go to the window again, with a viewport covering
+// it.
+func BindDefault() {
+	width, height := window.FramebufferSize()
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.Viewport(0, 0, int32(width), int32(height))
+}
+
+// Resolve copies the multisampled attachments to the textures, to be called
+// after drawing and before sampling them. It does nothing without
+// multisampling. The default framebuffer is bound afterwards.
+func (framebuffer *Framebuffer) Resolve() {
+	if framebuffer.resolve == nil {
+		return
+	}
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, framebuffer.Id)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, framebuffer.resolve.Id)
+
+	width, height := int32(framebuffer.Width), int32(framebuffer.Height)
+
+	// Blits copy one color attachment at a time, from the read buffer to the
+	// draw buffers
+	for i := range framebuffer.Color {
+		attachment := gl.COLOR_ATTACHMENT0 + uint32(i)
+		gl.ReadBuffer(attachment)
+		gl.DrawBuffers(1, &attachment)
+		gl.BlitFramebuffer(0, 0, width, height, 0, 0, width, height, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	}
+
+	if framebuffer.Depth != nil {
+		mask := uint32(gl.DEPTH_BUFFER_BIT)
+		if hasStencil(framebuffer.options.Depth) {
+			mask |= gl.STENCIL_BUFFER_BIT
+		}
+		gl.BlitFramebuffer(0, 0, width, height, 0, 0, width, height, mask, gl.NEAREST)
+	}
+
+	// Restore the draw buffers changed above
+	if count := len(framebuffer.Color); count > 0 {
+		drawBuffers := make([]uint32, count)
+		for i := range drawBuffers {
+			drawBuffers[i] = gl.COLOR_ATTACHMENT0 + uint32(i)
+		}
+		gl.DrawBuffers(int32(count), &drawBuffers[0])
+		gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gldebug.Check("framebuffer.Resolve")
+}
+
+// Label names the framebuffer and its textures in GL debug output.
+func (framebuffer *Framebuffer) Label(name string) {
+	gldebug.Label(gl.FRAMEBUFFER, framebuffer.Id, name)
+
+	for i, color := range framebuffer.Color {
+		color.Label(fmt.Sprintf("%s color %d", name, i))
+	}
+
+	if framebuffer.Depth != nil {
+		framebuffer.Depth.Label(name + " depth")
+	}
+
+	if framebuffer.resolve != nil {
+		gldebug.Label(gl.FRAMEBUFFER, framebuffer.resolve.Id, name+" resolve")
+	}
+}
+
+func (framebuffer *Framebuffer) deleteAttachments() {
+	// Textures belong to the resolve framebuffer with multisampling
+	if framebuffer.resolve == nil {
+		for _, color := range framebuffer.Color {
+			if color.Id != 0 {
+				color.Delete()
+			}
+		}
+
+		if framebuffer.Depth != nil && framebuffer.Depth.Id != 0 {
+			framebuffer.Depth.Delete()
+		}
+	}
+
+	if len(framebuffer.renderbuffers) > 0 {
+		gl.DeleteRenderbuffers(int32(len(framebuffer.renderbuffers)), &framebuffer.renderbuffers[0])
+		framebuffer.renderbuffers = nil
+	}
+}
+
+// Delete frees the framebuffer and its attachments and stops following window
+// resizes.
+func (framebuffer *Framebuffer) Delete() {
+	if framebuffer.removeResize != nil {
+		framebuffer.removeResize()
+		framebuffer.removeResize = nil
+	}
+
+	if framebuffer.resolve != nil {
+		framebuffer.resolve.Delete()
+	}
+
+	framebuffer.deleteAttachments()
+
+	if framebuffer.Id != 0 {
+		gl.DeleteFramebuffers(1, &framebuffer.Id)
+		framebuffer.Id = 0
+	}
+
+	// GL reuses the names of the textures deleted
+	material.Invalidate()
+}
@@ -0,0 +1,127 @@
+// Package geometry generates indexed meshes for common primitives on the CPU.
+//
+// All shapes are centered on the origin with Y up, front faces wound counter
+// clockwise (the GL default) and normals pointing outwards. Output only
+// depends on the arguments, the same call always returns the same vertices
+// in the same order.
+package geometry
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Vertex is tagged for utils.LayoutOf, so a Mesh can be uploaded with
+//
+//	mesh.New(m.Vertices, m.Indices, utils.MustLayoutOf(geometry.Vertex{}))
+type Vertex struct {
+	Position mgl32.Vec3 `gl:"location=0"`
+	Normal   mgl32.Vec3 `gl:"location=1"`
+	UV       mgl32.Vec2 `gl:"location=2"`
+	// Tangent points along increasing U, W is the handedness of the
+	// bitangent: bitangent = cross(normal, tangent.xyz) * tangent.w
+	Tangent mgl32.Vec4 `gl:"location=3"`
+}
+
+type Primitive int
+
+const (
+	Triangles Primitive = iota
+	// Lines index pairs of vertices, only Grid generates them.
+	Lines
+)
+
+type Mesh struct {
+	Primitive Primitive
+	Vertices  []Vertex
+	Indices   []uint32
+}
+
+// ComputeTangents fills in the vertices' tangents from their UVs, the
+// generators already call it. Vertices whose UVs don't vary, like the poles of
+// a sphere, get a tangent perpendicular to their normal.
+func (m *Mesh) ComputeTangents() {
+	if m.Primitive != Triangles {
+		return
+	}
+
+	tangents := make([]mgl32.Vec3, len(m.Vertices))
+	bitangents := make([]mgl32.Vec3, len(m.Vertices))
+
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		i0, i1, i2 := m.Indices[i], m.Indices[i+1], m.Indices[i+2]
+		v0, v1, v2 := m.Vertices[i0], m.Vertices[i1], m.Vertices[i2]
+
+		edge1 := v1.Position.Sub(v0.Position)
+		edge2 := v2.Position.Sub(v0.Position)
+		duv1 := v1.UV.Sub(v0.UV)
+		duv2 := v2.UV.Sub(v0.UV)
+
+		determinant := duv1.X()*duv2.Y() - duv2.X()*duv1.Y()
+		if determinant == 0 {
+			continue
+		}
+		r := 1 / determinant
+
+		tangent := edge1.Mul(duv2.Y()).Sub(edge2.Mul(duv1.Y())).Mul(r)
+		bitangent := edge2.Mul(duv1.X()).Sub(edge1.Mul(duv2.X())).Mul(r)
+
+		for _, index := range []uint32{i0, i1, i2} {
+			tangents[index] = tangents[index].Add(tangent)
+			bitangents[index] = bitangents[index].Add(bitangent)
+		}
+	}
+
+	for i := range m.Vertices {
+		normal := m.Vertices[i].Normal
+
+		// Gram-Schmidt orthogonalize against the normal
+		tangent := tangents[i].Sub(normal.Mul(normal.Dot(tangents[i])))
+		if tangent.Len() < 1e-6 {
+			tangent = perpendicular(normal)
+		}
+		tangent = tangent.Normalize()
+
+		handedness := float32(1)
+		if normal.Cross(tangent).Dot(bitangents[i]) < 0 {
+			handedness = -1
+		}
+
+		m.Vertices[i].Tangent = tangent.Vec4(handedness)
+	}
+}
+
+// perpendicular returns a unit vector perpendicular to normal.
+func perpendicular(normal mgl32.Vec3) mgl32.Vec3 {
+	axis := mgl32.Vec3{1, 0, 0}
+	if abs(normal.X()) > 0.9 {
+		axis = mgl32.Vec3{0, 1, 0}
+	}
+
+	return axis.Sub(normal.Mul(normal.Dot(axis))).Normalize()
+}
+
+func abs(value float32) float32 {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
+
+// append adds other's vertices and indices to m.
+func (m *Mesh) append(other *Mesh) {
+	base := uint32(len(m.Vertices))
+	m.Vertices = append(m.Vertices, other.Vertices...)
+
+	for _, index := range other.Indices {
+		m.Indices = append(m.Indices, base+index)
+	}
+}
+
+func atLeast(value int, minimum int) int {
+	if value < minimum {
+		return minimum
+	}
+
+	return value
+}
@@ -0,0 +1,93 @@
+package geometry
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestShapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		mesh      *Mesh
+		vertices  int
+		triangles int
+		// lines replaces triangles for Lines meshes, which get no other check.
+		lines int
+		// Convex shapes contain the origin, every face also points away from it.
+		convex bool
+	}{
+		{"plane", Plane(2, 3, 4, 2), 5 * 3, 2 * 4 * 2, 0, false},
+		{"box", Box(2, 1, 3, 2, 3, 4), 2 * (5*4 + 3*5 + 3*4), 2 * 2 * (4*3 + 2*4 + 2*3), 0, true},
+		{"sphere", UVSphere(1.5, 16, 8), 9 * 17, 2 * 16 * 7, 0, true},
+		{"icosphere", Icosphere(2, 2), 10*4*4 + 2, 20 * 4 * 4, 0, true},
+		{"cylinder", Cylinder(1, 2, 12, 3), 4*13 + 2*14, 2*12*3 + 2*12, 0, true},
+		{"cone", Cone(1, 2, 12, 3), 4*13 + 14, 2 * 12 * 3, 0, true},
+		{"torus", Torus(2, 0.5, 24, 12), 13 * 25, 2 * 24 * 12, 0, false},
+		{"capsule", Capsule(0.5, 1, 16, 4), 2 * 5 * 17, 2*16*9 - 2*16, 0, true},
+		{"grid", Grid(4, 5), 4 * 6, 0, 2 * 6, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := test.mesh
+
+			if len(m.Vertices) != test.vertices {
+				t.Errorf("%d vertices, want %d", len(m.Vertices), test.vertices)
+			}
+
+			if test.lines > 0 {
+				if m.Primitive != Lines || len(m.Indices) != 2*test.lines {
+					t.Errorf("%d indices of primitive %d, want %d lines", len(m.Indices), m.Primitive, test.lines)
+				}
+				return
+			}
+
+			if len(m.Indices) != 3*test.triangles {
+				t.Errorf("%d triangles, want %d", len(m.Indices)/3, test.triangles)
+			}
+
+			for i, vertex := range m.Vertices {
+				if length := vertex.Normal.Len(); abs(length-1) > 1e-5 {
+					t.Fatalf("vertex %d normal %v has length %v", i, vertex.Normal, length)
+				}
+			}
+
+			for i := 0; i < len(m.Indices); i += 3 {
+				a, b, c := m.Vertices[m.Indices[i]], m.Vertices[m.Indices[i+1]], m.Vertices[m.Indices[i+2]]
+				faceNormal := b.Position.Sub(a.Position).Cross(c.Position.Sub(a.Position))
+
+				if faceNormal.Len() < 1e-7 {
+					t.Fatalf("triangle %d is degenerate", i/3)
+				}
+
+				for _, vertex := range []Vertex{a, b, c} {
+					if vertex.Normal.Dot(faceNormal) <= 0 {
+						t.Fatalf("triangle %d faces away from the normal %v of its vertex at %v", i/3, vertex.Normal, vertex.Position)
+					}
+				}
+
+				centroid := a.Position.Add(b.Position).Add(c.Position).Mul(1.0 / 3)
+				if test.convex && centroid.Dot(faceNormal) <= 0 {
+					t.Fatalf("triangle %d at %v faces inwards", i/3, centroid)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendOffsetsIndices(t *testing.T) {
+	m := &Mesh{}
+	m.append(face(mgl32.Vec3{}, mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, 1}, 1, 1))
+	m.append(face(mgl32.Vec3{}, mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, 1}, 1, 1))
+
+	want := []uint32{0, 1, 3, 0, 3, 2, 4, 5, 7, 4, 7, 6}
+	if len(m.Indices) != len(want) {
+		t.Fatalf("indices %v, want %v", m.Indices, want)
+	}
+	for i := range want {
+		if m.Indices[i] != want[i] {
+			t.Fatalf("indices %v, want %v", m.Indices, want)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Plane lies in the XZ plane facing +Y, U runs along +X and V along -Z.
+func Plane(width float32, depth float32, segmentsX int, segmentsZ int) *Mesh {
+	segmentsX, segmentsZ = atLeast(segmentsX, 1), atLeast(segmentsZ, 1)
+
+	m := face(mgl32.Vec3{}, mgl32.Vec3{width, 0, 0}, mgl32.Vec3{0, 0, -depth}, mgl32.Vec3{0, 1, 0}, segmentsX, segmentsZ)
+	m.ComputeTangents()
+
+	return m
+}
+
+// Box has separate vertices per face so edges stay sharp, each face is
+// subdivided by the segments of the axes it spans and mapped to the whole
+// UV square.
+func Box(width float32, height float32, depth float32, segmentsX int, segmentsY int, segmentsZ int) *Mesh {
+	segmentsX, segmentsY, segmentsZ = atLeast(segmentsX, 1), atLeast(segmentsY, 1), atLeast(segmentsZ, 1)
+	w, h, d := width/2, height/2, depth/2
+
+	m := &Mesh{}
+	// +X, -X
+	m.append(face(mgl32.Vec3{w, 0, 0}, mgl32.Vec3{0, 0, -depth}, mgl32.Vec3{0, height, 0}, mgl32.Vec3{1, 0, 0}, segmentsZ, segmentsY))
+	m.append(face(mgl32.Vec3{-w, 0, 0}, mgl32.Vec3{0, 0, depth}, mgl32.Vec3{0, height, 0}, mgl32.Vec3{-1, 0, 0}, segmentsZ, segmentsY))
+	// +Y, -Y
+	m.append(face(mgl32.Vec3{0, h, 0}, mgl32.Vec3{width, 0, 0}, mgl32.Vec3{0, 0, -depth}, mgl32.Vec3{0, 1, 0}, segmentsX, segmentsZ))
+	m.append(face(mgl32.Vec3{0, -h, 0}, mgl32.Vec3{width, 0, 0}, mgl32.Vec3{0, 0, depth}, mgl32.Vec3{0, -1, 0}, segmentsX, segmentsZ))
+	// +Z, -Z
+	m.append(face(mgl32.Vec3{0, 0, d}, mgl32.Vec3{width, 0, 0}, mgl32.Vec3{0, height, 0}, mgl32.Vec3{0, 0, 1}, segmentsX, segmentsY))
+	m.append(face(mgl32.Vec3{0, 0, -d}, mgl32.Vec3{-width, 0, 0}, mgl32.Vec3{0, height, 0}, mgl32.Vec3{0, 0, -1}, segmentsX, segmentsY))
+
+	m.ComputeTangents()
+
+	return m
+}
+
+// UVSphere is made of segments slices around the Y axis and rings stacks from
+// pole to pole. U wraps around the equator, V runs from the south pole to the
+// north pole.
+func UVSphere(radius float32, segments int, rings int) *Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 2)
+
+	profile := make([]profilePoint, rings+1)
+	for k := range profile {
+		angle := float64(k) / float64(rings) * math.Pi
+		sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+
+		// Poles have to land exactly on the axis
+		if k == 0 || k == rings {
+			sin = 0
+		}
+
+		profile[k] = profilePoint{
+			Radius:       radius * sin,
+			Y:            -radius * cos,
+			NormalRadius: sin,
+			NormalY:      -cos,
+			V:            float32(k) / float32(rings),
+		}
+	}
+
+	m := revolve(profile, segments)
+	m.ComputeTangents()
+
+	return m
+}
+
+// Cylinder stands on the Y axis with caps at both ends.
+func Cylinder(radius float32, height float32, segments int, heightSegments int) *Mesh {
+	segments, heightSegments = atLeast(segments, 3), atLeast(heightSegments, 1)
+
+	profile := make([]profilePoint, heightSegments+1)
+	for k := range profile {
+		t := float32(k) / float32(heightSegments)
+
+		profile[k] = profilePoint{
+			Radius:       radius,
+			Y:            -height/2 + t*height,
+			NormalRadius: 1,
+			V:            t,
+		}
+	}
+
+	m := revolve(profile, segments)
+	m.append(disk(height/2, radius, segments, true))
+	m.append(disk(-height/2, radius, segments, false))
+	m.ComputeTangents()
+
+	return m
+}
+
+// Cone has its base cap at -height/2 and its apex at height/2. The apex is
+// split in one vertex per segment so the side keeps smooth normals.
+func Cone(radius float32, height float32, segments int, heightSegments int) *Mesh {
+	segments, heightSegments = atLeast(segments, 3), atLeast(heightSegments, 1)
+
+	profile := make([]profilePoint, heightSegments+1)
+	for k := range profile {
+		t := float32(k) / float32(heightSegments)
+
+		profile[k] = profilePoint{
+			Radius:       (1 - t) * radius,
+			Y:            -height/2 + t*height,
+			NormalRadius: height,
+			NormalY:      radius,
+			V:            t,
+		}
+	}
+	profile[heightSegments].Radius = 0
+
+	m := revolve(profile, segments)
+	m.append(disk(-height/2, radius, segments, false))
+	m.ComputeTangents()
+
+	return m
+}
+
+// Torus lies in the XZ plane. majorRadius is the distance from the center to
+// the middle of the tube, minorRadius the radius of the tube.
+func Torus(majorRadius float32, minorRadius float32, majorSegments int, minorSegments int) *Mesh {
+	majorSegments, minorSegments = atLeast(majorSegments, 3), atLeast(minorSegments, 3)
+
+	profile := make([]profilePoint, minorSegments+1)
+	for k := range profile {
+		angle := float64(k) / float64(minorSegments) * 2 * math.Pi
+		sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+
+		profile[k] = profilePoint{
+			Radius:       majorRadius + minorRadius*cos,
+			Y:            minorRadius * sin,
+			NormalRadius: cos,
+			NormalY:      sin,
+			V:            float32(k) / float32(minorSegments),
+		}
+	}
+
+	m := revolve(profile, majorSegments)
+	m.ComputeTangents()
+
+	return m
+}
+
+// Capsule is a cylinder of the given height capped with hemispheres, the total
+// height is height + 2*radius. rings is the number of stacks per hemisphere.
+// V is spread by arc length so textures don't stretch on the caps.
+func Capsule(radius float32, height float32, segments int, rings int) *Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 1)
+
+	length := math.Pi*float64(radius) + float64(height)
+	var profile []profilePoint
+
+	for hemisphere := 0; hemisphere < 2; hemisphere++ {
+		center := -height / 2
+		if hemisphere == 1 {
+			center = height / 2
+		}
+
+		for k := 0; k <= rings; k++ {
+			// Bottom hemisphere goes from the pole to the equator, the top one
+			// from the equator to the pole.
+			angle := (float64(hemisphere) + float64(k)/float64(rings)) * math.Pi / 2
+			sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+
+			if (hemisphere == 0 && k == 0) || (hemisphere == 1 && k == rings) {
+				sin = 0
+			}
+
+			arc := angle * float64(radius)
+			if hemisphere == 1 {
+				arc += float64(height)
+			}
+
+			profile = append(profile, profilePoint{
+				Radius:       radius * sin,
+				Y:            center - radius*cos,
+				NormalRadius: sin,
+				NormalY:      -cos,
+				V:            float32(arc / length),
+			})
+		}
+	}
+
+	m := revolve(profile, segments)
+	m.ComputeTangents()
+
+	return m
+}
+
+// Icosphere subdivides an icosahedron, its triangles are much more even than
+// those of a UVSphere. UVs use the same spherical mapping as UVSphere, but
+// there are no duplicated seam vertices so triangles crossing U = 0 smear the
+// whole texture.
+func Icosphere(radius float32, subdivisions int) *Mesh {
+	t := float32((1 + math.Sqrt(5)) / 2)
+
+	positions := []mgl32.Vec3{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	for i := range positions {
+		positions[i] = positions[i].Normalize()
+	}
+
+	indices := []uint32{
+		0, 11, 5, 0, 5, 1, 0, 1, 7, 0, 7, 10, 0, 10, 11,
+		1, 5, 9, 5, 11, 4, 11, 10, 2, 10, 7, 6, 7, 1, 8,
+		3, 9, 4, 3, 4, 2, 3, 2, 6, 3, 6, 8, 3, 8, 9,
+		4, 9, 5, 2, 4, 11, 6, 2, 10, 8, 6, 7, 9, 8, 1,
+	}
+
+	for s := 0; s < subdivisions; s++ {
+		midpoints := map[[2]uint32]uint32{}
+		midpoint := func(a, b uint32) uint32 {
+			key := [2]uint32{a, b}
+			if b < a {
+				key = [2]uint32{b, a}
+			}
+
+			if index, ok := midpoints[key]; ok {
+				return index
+			}
+
+			positions = append(positions, positions[a].Add(positions[b]).Normalize())
+			index := uint32(len(positions) - 1)
+			midpoints[key] = index
+
+			return index
+		}
+
+		subdivided := make([]uint32, 0, len(indices)*4)
+		for i := 0; i < len(indices); i += 3 {
+			a, b, c := indices[i], indices[i+1], indices[i+2]
+			ab, bc, ca := midpoint(a, b), midpoint(b, c), midpoint(c, a)
+
+			subdivided = append(subdivided,
+				a, ab, ca,
+				b, bc, ab,
+				c, ca, bc,
+				ab, bc, ca,
+			)
+		}
+		indices = subdivided
+	}
+
+	m := &Mesh{Indices: indices}
+	for _, position := range positions {
+		u := math.Atan2(float64(-position.Z()), float64(position.X())) / (2 * math.Pi)
+		if u < 0 {
+			u++
+		}
+		v := math.Acos(float64(mgl32.Clamp(-position.Y(), -1, 1))) / math.Pi
+
+		m.Vertices = append(m.Vertices, Vertex{
+			Position: position.Mul(radius),
+			Normal:   position,
+			UV:       mgl32.Vec2{float32(u), float32(v)},
+		})
+	}
+
+	m.ComputeTangents()
+
+	return m
+}
+
+// Grid is a square of lines in the XZ plane, divisions cells on each side.
+// It's a Lines mesh meant for reference grids in editors and debug views.
+func Grid(size float32, divisions int) *Mesh {
+	divisions = atLeast(divisions, 1)
+	half := size / 2
+
+	m := &Mesh{Primitive: Lines}
+	vertex := func(x, z float32) {
+		m.Vertices = append(m.Vertices, Vertex{
+			Position: mgl32.Vec3{x, 0, z},
+			Normal:   mgl32.Vec3{0, 1, 0},
+			UV:       mgl32.Vec2{x/size + 0.5, 0.5 - z/size},
+			Tangent:  mgl32.Vec4{1, 0, 0, 1},
+		})
+	}
+
+	for i := 0; i <= divisions; i++ {
+		offset := -half + float32(i)/float32(divisions)*size
+
+		// Line along Z, then along X
+		vertex(offset, -half)
+		vertex(offset, half)
+		vertex(-half, offset)
+		vertex(half, offset)
+
+		base := uint32(i * 4)
+		m.Indices = append(m.Indices, base, base+1, base+2, base+3)
+	}
+
+	return m
+}
@@ -0,0 +1,141 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// face builds a subdivided rectangle centered on center spanning uAxis and
+// vAxis. cross(uAxis, vAxis) must point along normal for the winding to be
+// counter clockwise seen from the front.
+func face(center, uAxis, vAxis, normal mgl32.Vec3, uSegments, vSegments int) *Mesh {
+	m := &Mesh{}
+
+	for j := 0; j <= vSegments; j++ {
+		v := float32(j) / float32(vSegments)
+
+		for i := 0; i <= uSegments; i++ {
+			u := float32(i) / float32(uSegments)
+
+			m.Vertices = append(m.Vertices, Vertex{
+				Position: center.Add(uAxis.Mul(u - 0.5)).Add(vAxis.Mul(v - 0.5)),
+				Normal:   normal,
+				UV:       mgl32.Vec2{u, v},
+			})
+		}
+	}
+
+	m.Indices = gridIndices(uSegments, vSegments, nil)
+
+	return m
+}
+
+// gridIndices triangulates a (uSegments+1) x (vSegments+1) grid of vertices
+// stored row by row. Each cell with corners a (i, j), b (i+1, j), c (i+1, j+1)
+// and d (i, j+1) becomes triangles abc and acd. collapsed reports rows whose
+// vertices all share one position, triangles with an edge on such a row are
+// degenerate and left out.
+func gridIndices(uSegments int, vSegments int, collapsed func(row int) bool) []uint32 {
+	var indices []uint32
+	stride := uint32(uSegments + 1)
+
+	for j := 0; j < vSegments; j++ {
+		for i := 0; i < uSegments; i++ {
+			a := uint32(j)*stride + uint32(i)
+			b := a + 1
+			c := b + stride
+			d := a + stride
+
+			if collapsed == nil || !collapsed(j) {
+				indices = append(indices, a, b, c)
+			}
+
+			if collapsed == nil || !collapsed(j+1) {
+				indices = append(indices, a, c, d)
+			}
+		}
+	}
+
+	return indices
+}
+
+// profilePoint is a point of the outline a surface of revolution is made
+// from, in the plane containing the Y axis.
+type profilePoint struct {
+	Radius float32
+	Y      float32
+	// Normal of the outline at the point, pointing away from the axis.
+	NormalRadius float32
+	NormalY      float32
+	V            float32
+}
+
+// revolve sweeps a profile, ordered from bottom to top, around the Y axis.
+// Points with a zero radius lie on the axis and close the surface.
+func revolve(profile []profilePoint, segments int) *Mesh {
+	m := &Mesh{}
+
+	for _, point := range profile {
+		for i := 0; i <= segments; i++ {
+			u := float32(i) / float32(segments)
+			angle := float64(u) * 2 * math.Pi
+			cos, sin := float32(math.Cos(angle)), float32(math.Sin(angle))
+
+			// Angles grow from +X towards -Z, counter clockwise seen from
+			// above, which makes the winding face outwards.
+			m.Vertices = append(m.Vertices, Vertex{
+				Position: mgl32.Vec3{point.Radius * cos, point.Y, -point.Radius * sin},
+				Normal:   mgl32.Vec3{point.NormalRadius * cos, point.NormalY, -point.NormalRadius * sin}.Normalize(),
+				UV:       mgl32.Vec2{u, point.V},
+			})
+		}
+	}
+
+	m.Indices = gridIndices(segments, len(profile)-1, func(row int) bool {
+		return profile[row].Radius == 0
+	})
+
+	return m
+}
+
+// disk is a cap at height y facing up or down.
+func disk(y float32, radius float32, segments int, up bool) *Mesh {
+	normal := mgl32.Vec3{0, -1, 0}
+	if up {
+		normal = mgl32.Vec3{0, 1, 0}
+	}
+
+	m := &Mesh{}
+	m.Vertices = append(m.Vertices, Vertex{
+		Position: mgl32.Vec3{0, y, 0},
+		Normal:   normal,
+		UV:       mgl32.Vec2{0.5, 0.5},
+	})
+
+	for i := 0; i <= segments; i++ {
+		angle := float64(i) / float64(segments) * 2 * math.Pi
+		cos, sin := float32(math.Cos(angle)), float32(math.Sin(angle))
+
+		uv := mgl32.Vec2{0.5 + 0.5*cos, 0.5 + 0.5*sin}
+		if !up {
+			uv[1] = 0.5 - 0.5*sin
+		}
+
+		m.Vertices = append(m.Vertices, Vertex{
+			Position: mgl32.Vec3{radius * cos, y, -radius * sin},
+			Normal:   normal,
+			UV:       uv,
+		})
+	}
+
+	for i := uint32(1); i <= uint32(segments); i++ {
+		if up {
+			m.Indices = append(m.Indices, 0, i, i+1)
+		} else {
+			m.Indices = append(m.Indices, 0, i+1, i)
+		}
+	}
+
+	return m
+}
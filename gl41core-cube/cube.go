@@ -2,21 +2,28 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Renders a textured spinning cube using GLFW 3 and OpenGL 4.1 core forward-compatible profile.
+// Renders a textured spinning cube using GLFW 3 and OpenGL 3.3 core forward-compatible profile.
 package main // import "github.com/go-gl/example/gl41core-cube"
 
 import (
 	"fmt"
 	"go/build"
 	"image"
-	"image/draw"
-	_ "image/png"
 	"log"
 	"os"
-	"runtime"
 	"strings"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/example/camera"
+	"github.com/go-gl/example/geometry"
+	"github.com/go-gl/example/material"
+	"github.com/go-gl/example/mesh"
+	"github.com/go-gl/example/scene"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/skybox"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/example/utils"
+	"github.com/go-gl/example/window"
+	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
@@ -24,119 +31,131 @@ import (
 const windowWidth = 800
 const windowHeight = 600
 
-func init() {
-	// GLFW event handling must run on the main OS thread
-	runtime.LockOSThread()
-}
+var program uint32
+var world *scene.Scene
+var cube *scene.Node
+var view *camera.Perspective
+var orbit *camera.Orbit
+var sky *skybox.Skybox
 
-func main() {
-	if err := glfw.Init(); err != nil {
-		log.Fatalln("failed to initialize glfw:", err)
-	}
-	defer glfw.Terminate()
-
-	glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-	window, err := glfw.CreateWindow(windowWidth, windowHeight, "Cube", nil, nil)
-	if err != nil {
-		panic(err)
-	}
-	window.MakeContextCurrent()
+var angle, previousTime float64
 
-	// Initialize Glow
-	if err := gl.Init(); err != nil {
-		panic(err)
-	}
+func main() {
+	window.Create(windowWidth, windowHeight, "Cube", onWindowStart, onWindowUpdate)
+}
 
+func onWindowStart() {
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	fmt.Println("OpenGL version", version)
 
 	// Configure the vertex and fragment shaders
-	program, err := newProgram(vertexShader, fragmentShader)
+	var err error
+	program, err = newProgram(vertexShader, fragmentShader)
 	if err != nil {
 		panic(err)
 	}
 
 	gl.UseProgram(program)
 
-	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
-	projectionUniform := gl.GetUniformLocation(program, gl.Str("projection\x00"))
-	gl.UniformMatrix4fv(projectionUniform, 1, false, &projection[0])
-
-	camera := mgl32.LookAtV(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
-	cameraUniform := gl.GetUniformLocation(program, gl.Str("camera\x00"))
-	gl.UniformMatrix4fv(cameraUniform, 1, false, &camera[0])
-
-	model := mgl32.Ident4()
-	modelUniform := gl.GetUniformLocation(program, gl.Str("model\x00"))
-	gl.UniformMatrix4fv(modelUniform, 1, false, &model[0])
+	// Drag to turn around the cube, scroll to zoom
+	view = camera.NewPerspective(mgl32.DegToRad(45.0), 0.1, 100.0)
+	orbit = camera.NewOrbit(mgl32.Vec3{0, 0, 0}, 1)
+	orbit.LookFrom(mgl32.Vec3{3, 3, 3})
 
-	textureUniform := gl.GetUniformLocation(program, gl.Str("tex\x00"))
-	gl.Uniform1i(textureUniform, 0)
+	// Load the texture, the material binds it for the tex sampler
+	squareTexture, err := texture.Load("square.png", texture.Options{Mipmaps: true, FlipY: true})
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	gl.BindFragDataLocation(program, 0, gl.Str("outputColor\x00"))
+	squareMaterial := material.New("square", shader.Shader{ProgramId: program})
+	squareMaterial.SetTexture("tex", squareTexture)
 
-	// Load the texture
-	texture, err := newTexture("square.png")
+	// Build the sky from a generated panorama
+	faces := texture.EquirectFaces(skyPanorama(512, 256), 128)
+	cubemap, err := texture.CubemapFromImages(faces, texture.Options{ColorSpace: texture.SRGB})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	sky, err = skybox.New(cubemap)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
 	// Configure the vertex data
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
+	box := geometry.Box(2, 2, 2, 1, 1, 1)
+	boxMesh := mesh.New(box.Vertices, box.Indices, utils.MustLayoutOf(geometry.Vertex{}))
 
-	var vbo uint32
-	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(cubeVertices)*4, gl.Ptr(cubeVertices), gl.STATIC_DRAW)
+	// A small cube circles the spinning one, carried around by it
+	renderable := &scene.Renderable{Mesh: boxMesh, Shader: squareMaterial.Shader, Material: squareMaterial}
 
-	vertAttrib := uint32(gl.GetAttribLocation(program, gl.Str("vert\x00")))
-	gl.EnableVertexAttribArray(vertAttrib)
-	gl.VertexAttribPointerWithOffset(vertAttrib, 3, gl.FLOAT, false, 5*4, 0)
+	world = scene.New()
+	cube = scene.NewNode("cube")
+	cube.Renderable = renderable
+	world.Root.Attach(cube)
 
-	texCoordAttrib := uint32(gl.GetAttribLocation(program, gl.Str("vertTexCoord\x00")))
-	gl.EnableVertexAttribArray(texCoordAttrib)
-	gl.VertexAttribPointerWithOffset(texCoordAttrib, 2, gl.FLOAT, false, 5*4, 3*4)
+	satellite := scene.NewNode("satellite")
+	satellite.Renderable = renderable
+	satellite.SetPosition(mgl32.Vec3{0, 0, 2.5})
+	satellite.SetScale(mgl32.Vec3{0.3, 0.3, 0.3})
+	cube.Attach(satellite)
 
 	// Configure global settings
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthFunc(gl.LESS)
 	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
 
-	angle := 0.0
-	previousTime := glfw.GetTime()
-
-	for !window.ShouldClose() {
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	previousTime = glfw.GetTime()
+}
 
-		// Update
-		time := glfw.GetTime()
-		elapsed := time - previousTime
-		previousTime = time
+func onWindowUpdate() {
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		angle += elapsed
-		model = mgl32.HomogRotate3D(float32(angle), mgl32.Vec3{0, 1, 0})
+	// Update
+	time := glfw.GetTime()
+	elapsed := time - previousTime
+	previousTime = time
 
-		// Render
-		gl.UseProgram(program)
-		gl.UniformMatrix4fv(modelUniform, 1, false, &model[0])
+	angle += elapsed
+	cube.SetRotation(mgl32.QuatRotate(float32(angle), mgl32.Vec3{0, 1, 0}))
 
-		gl.BindVertexArray(vao)
+	orbit.Update(&view.Transform, camera.ReadInput(float32(elapsed)))
 
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, texture)
+	// Render
+	world.Draw(view)
 
-		gl.DrawArrays(gl.TRIANGLES, 0, 6*2*3)
+	sky.Draw(view.View(), view.Projection())
+}
 
-		// Maintenance
-		window.SwapBuffers()
-		glfw.PollEvents()
+// skyPanorama is an equirectangular gradient from blue overhead to a pale
+// horizon and grey ground.
+func skyPanorama(width, height int) image.Image {
+	panorama := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	zenith := [3]float32{40, 100, 190}
+	horizon := [3]float32{200, 225, 245}
+	ground := [3]float32{90, 90, 95}
+
+	for y := 0; y < height; y++ {
+		// -1 at the bottom, 1 at the top
+		elevation := 1 - 2*(float32(y)+0.5)/float32(height)
+
+		var color [3]float32
+		for c := range color {
+			if elevation > 0 {
+				color[c] = horizon[c] + (zenith[c]-horizon[c])*elevation
+			} else {
+				color[c] = ground[c]
+			}
+		}
+
+		for x := 0; x < width; x++ {
+			pixel := panorama.Pix[y*panorama.Stride+x*4:]
+			pixel[0], pixel[1], pixel[2], pixel[3] = uint8(color[0]), uint8(color[1]), uint8(color[2]), 255
+		}
 	}
+
+	return panorama
 }
 
 func newProgram(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
@@ -197,44 +216,6 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 	return shader, nil
 }
 
-func newTexture(file string) (uint32, error) {
-	imgFile, err := os.Open(file)
-	if err != nil {
-		return 0, fmt.Errorf("texture %q not found on disk: %v", file, err)
-	}
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		return 0, err
-	}
-
-	rgba := image.NewRGBA(img.Bounds())
-	if rgba.Stride != rgba.Rect.Size().X*4 {
-		return 0, fmt.Errorf("unsupported stride")
-	}
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
-
-	var texture uint32
-	gl.GenTextures(1, &texture)
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.TexImage2D(
-		gl.TEXTURE_2D,
-		0,
-		gl.RGBA,
-		int32(rgba.Rect.Size().X),
-		int32(rgba.Rect.Size().Y),
-		0,
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		gl.Ptr(rgba.Pix))
-
-	return texture, nil
-}
-
 var vertexShader = `
 #version 330
 
@@ -242,8 +223,8 @@ uniform mat4 projection;
 uniform mat4 camera;
 uniform mat4 model;
 
-in vec3 vert;
-in vec2 vertTexCoord;
+layout(location = 0) in vec3 vert;
+layout(location = 2) in vec2 vertTexCoord;
 
 out vec2 fragTexCoord;
 
@@ -260,64 +241,13 @@ uniform sampler2D tex;
 
 in vec2 fragTexCoord;
 
-out vec4 outputColor;
+layout(location = 0) out vec4 outputColor;
 
 void main() {
     outputColor = texture(tex, fragTexCoord);
 }
 ` + "\x00"
 
-var cubeVertices = []float32{
-	//  X, Y, Z, U, V
-	// Bottom
-	-1.0, -1.0, -1.0, 0.0, 0.0,
-	1.0, -1.0, -1.0, 1.0, 0.0,
-	-1.0, -1.0, 1.0, 0.0, 1.0,
-	1.0, -1.0, -1.0, 1.0, 0.0,
-	1.0, -1.0, 1.0, 1.0, 1.0,
-	-1.0, -1.0, 1.0, 0.0, 1.0,
-
-	// Top
-	-1.0, 1.0, -1.0, 0.0, 0.0,
-	-1.0, 1.0, 1.0, 0.0, 1.0,
-	1.0, 1.0, -1.0, 1.0, 0.0,
-	1.0, 1.0, -1.0, 1.0, 0.0,
-	-1.0, 1.0, 1.0, 0.0, 1.0,
-	1.0, 1.0, 1.0, 1.0, 1.0,
-
-	// Front
-	-1.0, -1.0, 1.0, 1.0, 0.0,
-	1.0, -1.0, 1.0, 0.0, 0.0,
-	-1.0, 1.0, 1.0, 1.0, 1.0,
-	1.0, -1.0, 1.0, 0.0, 0.0,
-	1.0, 1.0, 1.0, 0.0, 1.0,
-	-1.0, 1.0, 1.0, 1.0, 1.0,
-
-	// Back
-	-1.0, -1.0, -1.0, 0.0, 0.0,
-	-1.0, 1.0, -1.0, 0.0, 1.0,
-	1.0, -1.0, -1.0, 1.0, 0.0,
-	1.0, -1.0, -1.0, 1.0, 0.0,
-	-1.0, 1.0, -1.0, 0.0, 1.0,
-	1.0, 1.0, -1.0, 1.0, 1.0,
-
-	// Left
-	-1.0, -1.0, 1.0, 0.0, 1.0,
-	-1.0, 1.0, -1.0, 1.0, 0.0,
-	-1.0, -1.0, -1.0, 0.0, 0.0,
-	-1.0, -1.0, 1.0, 0.0, 1.0,
-	-1.0, 1.0, 1.0, 1.0, 1.0,
-	-1.0, 1.0, -1.0, 1.0, 0.0,
-
-	// Right
-	1.0, -1.0, 1.0, 1.0, 1.0,
-	1.0, -1.0, -1.0, 1.0, 0.0,
-	1.0, 1.0, -1.0, 0.0, 0.0,
-	1.0, -1.0, 1.0, 1.0, 1.0,
-	1.0, 1.0, -1.0, 0.0, 0.0,
-	1.0, 1.0, 1.0, 0.0, 1.0,
-}
-
 // Set the working directory to the root of Go package, so that its assets can be accessed.
 func init() {
 	dir, err := importPathToDir("github.com/go-gl/example/gl41core-cube")
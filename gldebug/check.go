@@ -0,0 +1,54 @@
+//go:build gldebug
+
+package gldebug
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Check raises the error PanicOnError recorded, then logs every error raised
+// since the last check with call as the culprit. That part only runs when the
+// context has no debug output, KHR_debug already reports errors as they
+// happen.
+func Check(call string) {
+	panicFailed(call)
+
+	if !enabled || khrDebug {
+		return
+	}
+
+	for code := gl.GetError(); code != gl.NO_ERROR; code = gl.GetError() {
+		options.Logger.Log(context.Background(), slog.LevelError, errorName(code),
+			slog.String("call", call),
+		)
+
+		if options.PanicOnError {
+			panic("gl: " + errorName(code) + " after " + call)
+		}
+	}
+}
+
+func errorName(code uint32) string {
+	switch code {
+	case gl.INVALID_ENUM:
+		return "GL_INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		return "GL_INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		return "GL_INVALID_OPERATION"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		return "GL_INVALID_FRAMEBUFFER_OPERATION"
+	case gl.OUT_OF_MEMORY:
+		return "GL_OUT_OF_MEMORY"
+	case gl.STACK_OVERFLOW:
+		return "GL_STACK_OVERFLOW"
+	case gl.STACK_UNDERFLOW:
+		return "GL_STACK_UNDERFLOW"
+	default:
+		return fmt.Sprintf("0x%x", code)
+	}
+}
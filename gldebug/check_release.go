@@ -0,0 +1,9 @@
+//go:build !gldebug
+
+package gldebug
+
+// Check only raises errors recorded for Options.PanicOnError without the
+// gldebug build tag.
+func Check(call string) {
+	panicFailed(call)
+}
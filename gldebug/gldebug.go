@@ -0,0 +1,215 @@
+// Package gldebug routes OpenGL debug output (KHR_debug) into log/slog.
+//
+// Contexts without KHR_debug can't report errors on their own, when built
+// with the gldebug tag Check falls back to glGetError after the calls our
+// packages make. Without the tag Check only raises the errors PanicOnError
+// recorded.
+package gldebug
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unsafe"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+type Severity int
+
+const (
+	SeverityNotification Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+)
+
+type Options struct {
+	// Logger receives the messages, slog.Default() when nil.
+	Logger *slog.Logger
+
+	// Messages below MinSeverity are dropped.
+	MinSeverity Severity
+
+	// IgnoreIDs drops messages by their implementation specific ID, for
+	// drivers that are chatty about things we can't change.
+	IgnoreIDs []uint32
+
+	// PanicOnError panics on messages of type error, so the stack trace
+	// points near the call that caused it. Panicking can't unwind through
+	// the C frames calling the callback, so the error is recorded and the
+	// panic raised by the next Check. The callback is made synchronous so
+	// that's the Check after the failing call.
+	PanicOnError bool
+}
+
+var enabled bool
+var khrDebug bool
+var options Options
+var ignored map[uint32]bool
+
+// failed is the first error message recorded for PanicOnError since the last
+// Check, empty when there's none.
+var failed string
+
+// Enable starts logging GL messages, the context must be current. It returns
+// an error when the context has no debug output, Check is then the only way
+// errors get reported.
+func Enable(opts Options) error {
+	options = opts
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+
+	ignored = make(map[uint32]bool, len(options.IgnoreIDs))
+	for _, id := range options.IgnoreIDs {
+		ignored[id] = true
+	}
+
+	enabled = true
+	khrDebug = glfw.ExtensionSupported("GL_KHR_debug")
+
+	if !khrDebug {
+		return fmt.Errorf("GL_KHR_debug not supported by the context, falling back to glGetError")
+	}
+
+	gl.Enable(gl.DEBUG_OUTPUT)
+	if options.PanicOnError {
+		gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+	}
+
+	gl.DebugMessageCallback(onMessage, nil)
+
+	return nil
+}
+
+// Disable stops logging GL messages.
+func Disable() {
+	if khrDebug {
+		gl.DebugMessageCallback(nil, nil)
+		gl.Disable(gl.DEBUG_OUTPUT)
+	}
+
+	enabled = false
+	khrDebug = false
+}
+
+// Label names a GL object so debug messages and tools like RenderDoc show it.
+// identifier is the object's namespace, gl.BUFFER, gl.TEXTURE, gl.PROGRAM and
+// so on. Does nothing unless debug output is enabled.
+func Label(identifier uint32, name uint32, label string) {
+	if !khrDebug || name == 0 {
+		return
+	}
+
+	gl.ObjectLabel(identifier, name, int32(len(label)), gl.Str(label+"\x00"))
+}
+
+func onMessage(source uint32, gltype uint32, id uint32, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+	level := toSeverity(severity)
+	if level < options.MinSeverity || ignored[id] {
+		return
+	}
+
+	options.Logger.Log(context.Background(), slogLevel(level), message,
+		slog.String("source", sourceName(source)),
+		slog.String("type", typeName(gltype)),
+		slog.String("severity", severityName(level)),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	if options.PanicOnError && gltype == gl.DEBUG_TYPE_ERROR && failed == "" {
+		failed = message
+	}
+}
+
+// panicFailed raises the error recorded by the callback, if any.
+func panicFailed(call string) {
+	if failed == "" {
+		return
+	}
+
+	message := failed
+	failed = ""
+	panic("gl: " + message + " in " + call)
+}
+
+func toSeverity(severity uint32) Severity {
+	switch severity {
+	case gl.DEBUG_SEVERITY_HIGH:
+		return SeverityHigh
+	case gl.DEBUG_SEVERITY_MEDIUM:
+		return SeverityMedium
+	case gl.DEBUG_SEVERITY_LOW:
+		return SeverityLow
+	default:
+		return SeverityNotification
+	}
+}
+
+func slogLevel(severity Severity) slog.Level {
+	switch severity {
+	case SeverityHigh:
+		return slog.LevelError
+	case SeverityMedium:
+		return slog.LevelWarn
+	case SeverityLow:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+func severityName(severity Severity) string {
+	switch severity {
+	case SeverityHigh:
+		return "high"
+	case SeverityMedium:
+		return "medium"
+	case SeverityLow:
+		return "low"
+	default:
+		return "notification"
+	}
+}
+
+func sourceName(source uint32) string {
+	switch source {
+	case gl.DEBUG_SOURCE_API:
+		return "api"
+	case gl.DEBUG_SOURCE_WINDOW_SYSTEM:
+		return "window system"
+	case gl.DEBUG_SOURCE_SHADER_COMPILER:
+		return "shader compiler"
+	case gl.DEBUG_SOURCE_THIRD_PARTY:
+		return "third party"
+	case gl.DEBUG_SOURCE_APPLICATION:
+		return "application"
+	default:
+		return "other"
+	}
+}
+
+func typeName(gltype uint32) string {
+	switch gltype {
+	case gl.DEBUG_TYPE_ERROR:
+		return "error"
+	case gl.DEBUG_TYPE_DEPRECATED_BEHAVIOR:
+		return "deprecated behavior"
+	case gl.DEBUG_TYPE_UNDEFINED_BEHAVIOR:
+		return "undefined behavior"
+	case gl.DEBUG_TYPE_PORTABILITY:
+		return "portability"
+	case gl.DEBUG_TYPE_PERFORMANCE:
+		return "performance"
+	case gl.DEBUG_TYPE_MARKER:
+		return "marker"
+	case gl.DEBUG_TYPE_PUSH_GROUP:
+		return "push group"
+	case gl.DEBUG_TYPE_POP_GROUP:
+		return "pop group"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,333 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Component types, the GL enums of the same name.
+const (
+	Byte          = 5120
+	UnsignedByte  = 5121
+	Short         = 5122
+	UnsignedShort = 5123
+	UnsignedInt   = 5125
+	Float         = 5126
+)
+
+// Accessor is a typed view of buffer data, decoded when the model is loaded.
+type Accessor struct {
+	Name          string
+	ComponentType int
+	// Type is SCALAR, VEC2, VEC3, VEC4, MAT2, MAT3 or MAT4.
+	Type       string
+	Normalized bool
+	Count      int
+
+	// Floats holds Count * Components() values, matrices in column major
+	// order. Normalized integers are mapped to [0, 1] or [-1, 1], other
+	// integers keep their value.
+	Floats []float32
+	// Uints holds the same values for unsigned integer component types,
+	// exact even past 2^24 where float32 isn't.
+	Uints []uint32
+}
+
+var numberOfComponents = map[string]int{
+	"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16,
+}
+
+// maxZeroValues caps accessors without a buffer view, they aren't backed by
+// any data so nothing else bounds their count.
+const maxZeroValues = 1 << 24
+
+var componentSizes = map[int]int{
+	Byte: 1, UnsignedByte: 1, Short: 2, UnsignedShort: 2, UnsignedInt: 4, Float: 4,
+}
+
+// Components is the number of values per element, 16 for a MAT4.
+func (accessor *Accessor) Components() int {
+	return numberOfComponents[accessor.Type]
+}
+
+func (accessor *Accessor) Vec2(i int) mgl32.Vec2 {
+	var v mgl32.Vec2
+	copy(v[:], accessor.Floats[i*accessor.Components():])
+	return v
+}
+
+func (accessor *Accessor) Vec3(i int) mgl32.Vec3 {
+	var v mgl32.Vec3
+	copy(v[:], accessor.Floats[i*accessor.Components():])
+	return v
+}
+
+func (accessor *Accessor) Vec4(i int) mgl32.Vec4 {
+	var v mgl32.Vec4
+	copy(v[:], accessor.Floats[i*accessor.Components():])
+	return v
+}
+
+func (accessor *Accessor) Mat4(i int) mgl32.Mat4 {
+	var m mgl32.Mat4
+	copy(m[:], accessor.Floats[i*16:])
+	return m
+}
+
+// elementLayout returns the offsets of an element's components relative to
+// its start and the size of the element. Matrix columns start on 4 byte
+// boundaries, which pads MAT2 of bytes and MAT3 of bytes or shorts.
+func elementLayout(accessorType string, componentSize int) ([]int, int) {
+	rows := 0
+	switch accessorType {
+	case "MAT2":
+		rows = 2
+	case "MAT3":
+		rows = 3
+	case "MAT4":
+		rows = 4
+	}
+
+	count := numberOfComponents[accessorType]
+	offsets := make([]int, count)
+
+	if rows == 0 {
+		for i := range offsets {
+			offsets[i] = i * componentSize
+		}
+		return offsets, count * componentSize
+	}
+
+	columnSize := (rows*componentSize + 3) &^ 3
+	for i := range offsets {
+		offsets[i] = i/rows*columnSize + i%rows*componentSize
+	}
+
+	return offsets, rows * columnSize
+}
+
+// decodeAccessor validates accessor index and reads its data, applying sparse
+// substitution. Errors name the accessor and, for range problems, the buffer
+// view.
+func (l *loader) decodeAccessor(index int) (*Accessor, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d doesn't exist", index)
+	}
+
+	if accessor := l.accessors[index]; accessor != nil {
+		return accessor, nil
+	}
+
+	source := l.doc.Accessors[index]
+	accessor, err := l.readAccessor(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", describe("accessor", index, source.Name), err)
+	}
+
+	l.accessors[index] = accessor
+	return accessor, nil
+}
+
+func (l *loader) readAccessor(source documentAccessor) (*Accessor, error) {
+	components, ok := numberOfComponents[source.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid type %q", source.Type)
+	}
+
+	componentSize, ok := componentSizes[source.ComponentType]
+	if !ok {
+		return nil, fmt.Errorf("invalid component type %d", source.ComponentType)
+	}
+
+	if source.Count < 1 {
+		return nil, fmt.Errorf("count %d is less than 1", source.Count)
+	}
+
+	if source.Normalized && (source.ComponentType == Float || source.ComponentType == UnsignedInt) {
+		return nil, fmt.Errorf("component type %d can't be normalized", source.ComponentType)
+	}
+
+	if source.ByteOffset < 0 {
+		return nil, fmt.Errorf("byte offset %d is negative", source.ByteOffset)
+	}
+
+	// Check the data is there before allocating anything, Count comes straight
+	// from the file.
+	var view bufferView
+	var offsets []int
+	var elementSize, stride int
+
+	if source.BufferView != nil {
+		if source.ByteOffset%componentSize != 0 {
+			return nil, fmt.Errorf("byte offset %d isn't a multiple of the component size %d", source.ByteOffset, componentSize)
+		}
+
+		offsets, elementSize = elementLayout(source.Type, componentSize)
+
+		var err error
+		view, err = l.bufferView(*source.BufferView)
+		if err != nil {
+			return nil, err
+		}
+
+		stride = elementSize
+		if view.stride != 0 {
+			stride = view.stride
+			if stride < elementSize {
+				return nil, fmt.Errorf("%s: byte stride %d is less than the element size %d", view.name, stride, elementSize)
+			}
+		}
+
+		// Written to not overflow: ByteOffset + stride*(Count-1) + elementSize <= len
+		available := len(view.data) - elementSize - source.ByteOffset
+		if available < 0 || (source.Count-1) > available/stride {
+			return nil, fmt.Errorf("%d elements at byte %d don't fit in %s (%d bytes)", source.Count, source.ByteOffset, view.name, len(view.data))
+		}
+	} else if source.Count > maxZeroValues/components {
+		return nil, fmt.Errorf("count %d is too large for an accessor without a buffer view", source.Count)
+	}
+
+	accessor := &Accessor{
+		Name:          source.Name,
+		ComponentType: source.ComponentType,
+		Type:          source.Type,
+		Normalized:    source.Normalized,
+		Count:         source.Count,
+		Floats:        make([]float32, source.Count*components),
+	}
+
+	unsigned := source.ComponentType == UnsignedByte || source.ComponentType == UnsignedShort || source.ComponentType == UnsignedInt
+	if unsigned {
+		accessor.Uints = make([]uint32, source.Count*components)
+	}
+
+	// Without a buffer view the accessor is all zeros, unless sparse
+	// values override some of it.
+	if source.BufferView != nil {
+		for i := 0; i < source.Count; i++ {
+			element := view.data[source.ByteOffset+i*stride:]
+			for c, offset := range offsets {
+				accessor.set(i*components+c, element[offset:])
+			}
+		}
+	}
+
+	if source.Sparse != nil {
+		if err := l.applySparse(accessor, source.Sparse, componentSize); err != nil {
+			return nil, fmt.Errorf("sparse: %v", err)
+		}
+	}
+
+	return accessor, nil
+}
+
+func (l *loader) applySparse(accessor *Accessor, sparse *documentSparse, componentSize int) error {
+	if sparse.Count < 1 || sparse.Count > accessor.Count {
+		return fmt.Errorf("count %d out of range of %d", sparse.Count, accessor.Count)
+	}
+
+	indexSize, ok := componentSizes[sparse.Indices.ComponentType]
+	if !ok || sparse.Indices.ComponentType == Byte || sparse.Indices.ComponentType == Short || sparse.Indices.ComponentType == Float {
+		return fmt.Errorf("invalid index component type %d", sparse.Indices.ComponentType)
+	}
+
+	indexView, err := l.bufferView(sparse.Indices.BufferView)
+	if err != nil {
+		return fmt.Errorf("indices: %v", err)
+	}
+
+	if !fits(sparse.Indices.ByteOffset, sparse.Count, indexSize, len(indexView.data)) {
+		return fmt.Errorf("%d indices at byte %d don't fit in %s (%d bytes)", sparse.Count, sparse.Indices.ByteOffset, indexView.name, len(indexView.data))
+	}
+
+	valueView, err := l.bufferView(sparse.Values.BufferView)
+	if err != nil {
+		return fmt.Errorf("values: %v", err)
+	}
+
+	// Sparse values are tightly packed, whatever the view's stride
+	offsets, elementSize := elementLayout(accessor.Type, componentSize)
+	if !fits(sparse.Values.ByteOffset, sparse.Count, elementSize, len(valueView.data)) {
+		return fmt.Errorf("%d values at byte %d don't fit in %s (%d bytes)", sparse.Count, sparse.Values.ByteOffset, valueView.name, len(valueView.data))
+	}
+
+	components := accessor.Components()
+	previous := -1
+
+	for i := 0; i < sparse.Count; i++ {
+		index := int(readUint(indexView.data[sparse.Indices.ByteOffset+i*indexSize:], sparse.Indices.ComponentType))
+		if index <= previous {
+			return fmt.Errorf("indices aren't strictly increasing at %d", i)
+		}
+		if index >= accessor.Count {
+			return fmt.Errorf("index %d out of range of %d", index, accessor.Count)
+		}
+		previous = index
+
+		element := valueView.data[sparse.Values.ByteOffset+i*elementSize:]
+		for c, offset := range offsets {
+			accessor.set(index*components+c, element[offset:])
+		}
+	}
+
+	return nil
+}
+
+// fits reports whether count tightly packed elements of size bytes starting
+// at offset lie within length bytes, without overflowing on hostile values.
+func fits(offset int, count int, size int, length int) bool {
+	return offset >= 0 && count >= 0 && offset <= length && count <= (length-offset)/size
+}
+
+// set decodes the component at the start of data into value i.
+func (accessor *Accessor) set(i int, data []byte) {
+	var value float32
+
+	switch accessor.ComponentType {
+	case Byte:
+		v := int8(data[0])
+		value = float32(v)
+		if accessor.Normalized {
+			value = max(value/127, -1)
+		}
+	case UnsignedByte:
+		value = float32(data[0])
+		if accessor.Normalized {
+			value /= 255
+		}
+	case Short:
+		v := int16(binary.LittleEndian.Uint16(data))
+		value = float32(v)
+		if accessor.Normalized {
+			value = max(value/32767, -1)
+		}
+	case UnsignedShort:
+		value = float32(binary.LittleEndian.Uint16(data))
+		if accessor.Normalized {
+			value /= 65535
+		}
+	case UnsignedInt:
+		value = float32(binary.LittleEndian.Uint32(data))
+	case Float:
+		value = math.Float32frombits(binary.LittleEndian.Uint32(data))
+	}
+
+	accessor.Floats[i] = value
+	if accessor.Uints != nil {
+		accessor.Uints[i] = readUint(data, accessor.ComponentType)
+	}
+}
+
+func readUint(data []byte, componentType int) uint32 {
+	switch componentType {
+	case UnsignedByte:
+		return uint32(data[0])
+	case UnsignedShort:
+		return uint32(binary.LittleEndian.Uint16(data))
+	default:
+		return binary.LittleEndian.Uint32(data)
+	}
+}
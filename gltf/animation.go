@@ -0,0 +1,233 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type Skin struct {
+	Name string
+	// Joints are the nodes making up the skeleton, JOINTS_n attributes index
+	// into it.
+	Joints []int
+	// Skeleton is the common root of the joints, -1 when unspecified.
+	Skeleton int
+	// InverseBindMatrices has one matrix per joint, identities when the file
+	// has none.
+	InverseBindMatrices []mgl32.Mat4
+}
+
+type Interpolation string
+
+const (
+	Linear      Interpolation = "LINEAR"
+	Step        Interpolation = "STEP"
+	CubicSpline Interpolation = "CUBICSPLINE"
+)
+
+type Animation struct {
+	Name     string
+	Channels []Channel
+	Samplers []AnimationSampler
+}
+
+// Channel animates one property of a node. Path is translation, rotation,
+// scale or weights.
+type Channel struct {
+	Sampler int
+	Node    int
+	Path    string
+}
+
+// AnimationSampler holds keyframes. Output has one value per input time, or
+// in-tangent, value and out-tangent for cubic splines. Rotations are x, y, z,
+// w quaternions.
+type AnimationSampler struct {
+	Input         []float32
+	Output        *Accessor
+	Interpolation Interpolation
+}
+
+// Duration is the time of the animation's last keyframe.
+func (animation *Animation) Duration() float32 {
+	var duration float32
+	for _, sampler := range animation.Samplers {
+		if len(sampler.Input) > 0 {
+			duration = max(duration, sampler.Input[len(sampler.Input)-1])
+		}
+	}
+
+	return duration
+}
+
+func (l *loader) loadSkins() error {
+	for i, source := range l.doc.Skins {
+		skin, err := l.skin(source)
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("skin", i, source.Name), err)
+		}
+
+		l.model.Skins = append(l.model.Skins, skin)
+	}
+
+	return nil
+}
+
+func (l *loader) skin(source documentSkin) (Skin, error) {
+	if len(source.Joints) == 0 {
+		return Skin{}, fmt.Errorf("no joints")
+	}
+
+	for _, joint := range source.Joints {
+		if joint < 0 || joint >= len(l.model.Nodes) {
+			return Skin{}, fmt.Errorf("joint node %d doesn't exist", joint)
+		}
+	}
+
+	skin := Skin{Name: source.Name, Joints: source.Joints}
+
+	var err error
+	if skin.Skeleton, err = reference(source.Skeleton, len(l.model.Nodes), "skeleton node"); err != nil {
+		return Skin{}, err
+	}
+
+	skin.InverseBindMatrices = make([]mgl32.Mat4, len(source.Joints))
+
+	if source.InverseBindMatrices == nil {
+		for i := range skin.InverseBindMatrices {
+			skin.InverseBindMatrices[i] = mgl32.Ident4()
+		}
+		return skin, nil
+	}
+
+	accessor, err := l.decodeAccessor(*source.InverseBindMatrices)
+	if err != nil {
+		return Skin{}, err
+	}
+
+	if accessor.Type != "MAT4" || accessor.ComponentType != Float || accessor.Count < len(source.Joints) {
+		return Skin{}, fmt.Errorf("%s: inverse bind matrices need %d float MAT4",
+			describe("accessor", *source.InverseBindMatrices, accessor.Name), len(source.Joints))
+	}
+
+	for i := range skin.InverseBindMatrices {
+		skin.InverseBindMatrices[i] = accessor.Mat4(i)
+	}
+
+	return skin, nil
+}
+
+func (l *loader) loadAnimations() error {
+	for i, source := range l.doc.Animations {
+		animation, err := l.animation(source)
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("animation", i, source.Name), err)
+		}
+
+		l.model.Animations = append(l.model.Animations, animation)
+	}
+
+	return nil
+}
+
+func (l *loader) animation(source documentAnimation) (Animation, error) {
+	animation := Animation{Name: source.Name}
+
+	for i, samplerSource := range source.Samplers {
+		sampler := AnimationSampler{Interpolation: Interpolation(samplerSource.Interpolation)}
+
+		switch sampler.Interpolation {
+		case "":
+			sampler.Interpolation = Linear
+		case Linear, Step, CubicSpline:
+		default:
+			return Animation{}, fmt.Errorf("sampler %d: invalid interpolation %q", i, samplerSource.Interpolation)
+		}
+
+		input, err := l.decodeAccessor(samplerSource.Input)
+		if err != nil {
+			return Animation{}, fmt.Errorf("sampler %d: input: %v", i, err)
+		}
+		if input.Type != "SCALAR" || input.ComponentType != Float {
+			return Animation{}, fmt.Errorf("sampler %d: %s: input must be float scalars",
+				i, describe("accessor", samplerSource.Input, input.Name))
+		}
+		sampler.Input = input.Floats
+
+		if sampler.Output, err = l.decodeAccessor(samplerSource.Output); err != nil {
+			return Animation{}, fmt.Errorf("sampler %d: output: %v", i, err)
+		}
+
+		animation.Samplers = append(animation.Samplers, sampler)
+	}
+
+	for i, channelSource := range source.Channels {
+		channel := Channel{Sampler: channelSource.Sampler, Path: channelSource.Target.Path}
+
+		if channel.Sampler < 0 || channel.Sampler >= len(animation.Samplers) {
+			return Animation{}, fmt.Errorf("channel %d: sampler %d doesn't exist", i, channel.Sampler)
+		}
+
+		var err error
+		if channel.Node, err = reference(channelSource.Target.Node, len(l.model.Nodes), "node"); err != nil {
+			return Animation{}, fmt.Errorf("channel %d: %v", i, err)
+		}
+
+		// Channels without a node are meant for extensions
+		if channel.Node < 0 {
+			continue
+		}
+
+		if err := checkChannelOutput(animation.Samplers[channel.Sampler], channel.Path, l.morphTargets(channel.Node)); err != nil {
+			return Animation{}, fmt.Errorf("channel %d: %v", i, err)
+		}
+
+		animation.Channels = append(animation.Channels, channel)
+	}
+
+	return animation, nil
+}
+
+// morphTargets is the number of morph targets of the node's mesh.
+func (l *loader) morphTargets(node int) int {
+	mesh := l.model.Nodes[node].Mesh
+	if mesh < 0 || len(l.model.Meshes[mesh].Primitives) == 0 {
+		return 0
+	}
+
+	return len(l.model.Meshes[mesh].Primitives[0].Targets)
+}
+
+// checkChannelOutput makes sure the sampler has as many values of the right
+// type as the path and interpolation need.
+func checkChannelOutput(sampler AnimationSampler, path string, morphTargets int) error {
+	accessorType, perKeyframe := "", 1
+
+	switch path {
+	case "translation", "scale":
+		accessorType = "VEC3"
+	case "rotation":
+		accessorType = "VEC4"
+	case "weights":
+		accessorType = "SCALAR"
+		perKeyframe = morphTargets
+	default:
+		return fmt.Errorf("invalid path %q", path)
+	}
+
+	if sampler.Output.Type != accessorType {
+		return fmt.Errorf("%s output must be %s, not %s", path, accessorType, sampler.Output.Type)
+	}
+
+	expected := len(sampler.Input) * perKeyframe
+	if sampler.Interpolation == CubicSpline {
+		expected *= 3
+	}
+
+	if sampler.Output.Count != expected {
+		return fmt.Errorf("output has %d values for %d keyframes, expected %d", sampler.Output.Count, len(sampler.Input), expected)
+	}
+
+	return nil
+}
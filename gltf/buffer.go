@@ -0,0 +1,176 @@
+package gltf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	glbMagic     = 0x46546c67 // "glTF"
+	glbChunkJSON = 0x4e4f534a // "JSON"
+	glbChunkBIN  = 0x004e4942 // "BIN\0"
+)
+
+// isGLB tells binary glTF from JSON by its magic number.
+func isGLB(data []byte) bool {
+	return len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic
+}
+
+// splitGLB returns the JSON and binary chunks of a GLB file, binaryChunk is
+// nil when the file has none.
+func splitGLB(data []byte) (jsonChunk []byte, binaryChunk []byte, err error) {
+	if len(data) < 12 {
+		return nil, nil, fmt.Errorf("glb: header truncated")
+	}
+
+	if version := binary.LittleEndian.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("glb: unsupported version %d", version)
+	}
+
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("glb: header says %d bytes, file has %d", length, len(data))
+	}
+	data = data[12:length]
+
+	for chunk := 0; len(data) > 0; chunk++ {
+		if len(data) < 8 {
+			return nil, nil, fmt.Errorf("glb: chunk %d header truncated", chunk)
+		}
+
+		chunkLength := int(binary.LittleEndian.Uint32(data))
+		chunkType := binary.LittleEndian.Uint32(data[4:])
+		if chunkLength > len(data)-8 {
+			return nil, nil, fmt.Errorf("glb: chunk %d needs %d bytes, %d left", chunk, chunkLength, len(data)-8)
+		}
+
+		content := data[8 : 8+chunkLength]
+		data = data[8+chunkLength:]
+
+		switch {
+		case chunk == 0 && chunkType != glbChunkJSON:
+			return nil, nil, fmt.Errorf("glb: first chunk isn't JSON")
+		case chunk == 0:
+			jsonChunk = content
+		case chunk == 1 && chunkType == glbChunkBIN:
+			binaryChunk = content
+		}
+		// Unknown chunks are skipped as the spec requires
+	}
+
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("glb: no JSON chunk")
+	}
+
+	return jsonChunk, binaryChunk, nil
+}
+
+// readURI resolves a buffer or image URI, either a base64 data URI or a path
+// relative to the glTF file.
+func (l *loader) readURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("data URI isn't base64")
+		}
+
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+
+	if l.fsys == nil {
+		return nil, fmt.Errorf("external URI %q needs Load", uri)
+	}
+
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI %q", uri)
+	}
+
+	return fs.ReadFile(l.fsys, path.Join(l.dir, name))
+}
+
+func (l *loader) loadBuffers() error {
+	l.buffers = make([][]byte, len(l.doc.Buffers))
+
+	for i, buffer := range l.doc.Buffers {
+		var data []byte
+		var err error
+
+		switch {
+		case buffer.URI != "":
+			data, err = l.readURI(buffer.URI)
+		case i == 0 && l.binary != nil:
+			data = l.binary
+		default:
+			err = fmt.Errorf("no URI and no GLB binary chunk")
+		}
+
+		if err == nil && buffer.ByteLength < 0 {
+			err = fmt.Errorf("byte length %d is negative", buffer.ByteLength)
+		}
+
+		if err == nil && len(data) < buffer.ByteLength {
+			err = fmt.Errorf("byte length is %d but data has %d bytes", buffer.ByteLength, len(data))
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("buffer", i, buffer.Name), err)
+		}
+
+		// The binary chunk may be padded past byteLength
+		l.buffers[i] = data[:buffer.ByteLength]
+	}
+
+	return nil
+}
+
+type bufferView struct {
+	name   string
+	data   []byte
+	stride int
+}
+
+// bufferView validates a buffer view and returns its bytes. The view's name
+// is part of every error so they can be told apart from accessor errors.
+func (l *loader) bufferView(index int) (bufferView, error) {
+	if index < 0 || index >= len(l.doc.BufferViews) {
+		return bufferView{}, fmt.Errorf("buffer view %d doesn't exist", index)
+	}
+
+	view := l.doc.BufferViews[index]
+	name := describe("buffer view", index, view.Name)
+
+	if view.Buffer < 0 || view.Buffer >= len(l.buffers) {
+		return bufferView{}, fmt.Errorf("%s: buffer %d doesn't exist", name, view.Buffer)
+	}
+
+	if view.ByteStride != 0 && (view.ByteStride < 4 || view.ByteStride > 252 || view.ByteStride%4 != 0) {
+		return bufferView{}, fmt.Errorf("%s: byte stride %d isn't a multiple of 4 between 4 and 252", name, view.ByteStride)
+	}
+
+	buffer := l.buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 1 || view.ByteOffset > len(buffer) || view.ByteLength > len(buffer)-view.ByteOffset {
+		return bufferView{}, fmt.Errorf("%s: %d bytes at byte %d out of range of buffer %d (%d bytes)",
+			name, view.ByteLength, view.ByteOffset, view.Buffer, len(buffer))
+	}
+
+	return bufferView{
+		name:   name,
+		data:   buffer[view.ByteOffset : view.ByteOffset+view.ByteLength],
+		stride: view.ByteStride,
+	}, nil
+}
+
+// describe names an object in errors, with its name when it has one.
+func describe(kind string, index int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("%s %d", kind, index)
+	}
+
+	return fmt.Sprintf("%s %d (%q)", kind, index, name)
+}
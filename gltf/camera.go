@@ -0,0 +1,92 @@
+package gltf
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Camera has either a perspective or an orthographic projection, looking
+// down -Z of its node.
+type Camera struct {
+	Name         string
+	Perspective  *Perspective
+	Orthographic *Orthographic
+}
+
+type Perspective struct {
+	// AspectRatio is 0 when the viewport's should be used.
+	AspectRatio float32 `json:"aspectRatio"`
+	// YFov is the vertical field of view in radians.
+	YFov  float32 `json:"yfov"`
+	ZNear float32 `json:"znear"`
+	// ZFar is 0 for an infinite projection.
+	ZFar float32 `json:"zfar"`
+}
+
+type Orthographic struct {
+	XMag  float32 `json:"xmag"`
+	YMag  float32 `json:"ymag"`
+	ZNear float32 `json:"znear"`
+	ZFar  float32 `json:"zfar"`
+}
+
+// Projection returns the camera's projection matrix, aspectRatio is used for
+// perspective cameras that don't have one.
+func (camera *Camera) Projection(aspectRatio float32) mgl32.Mat4 {
+	if camera.Orthographic != nil {
+		o := camera.Orthographic
+		return mgl32.Ortho(-o.XMag, o.XMag, -o.YMag, o.YMag, o.ZNear, o.ZFar)
+	}
+
+	p := camera.Perspective
+	if p.AspectRatio > 0 {
+		aspectRatio = p.AspectRatio
+	}
+
+	if p.ZFar > 0 {
+		return mgl32.Perspective(p.YFov, aspectRatio, p.ZNear, p.ZFar)
+	}
+
+	// The limit of the finite projection as zfar goes to infinity
+	f := float32(1 / math.Tan(float64(p.YFov)/2))
+	return mgl32.Mat4{
+		f / aspectRatio, 0, 0, 0,
+		0, f, 0, 0,
+		0, 0, -1, -1,
+		0, 0, -2 * p.ZNear, 0,
+	}
+}
+
+func (l *loader) loadCameras() error {
+	for i, source := range l.doc.Cameras {
+		camera := Camera{Name: source.Name}
+
+		var err error
+		switch source.Type {
+		case "perspective":
+			camera.Perspective = source.Perspective
+			if camera.Perspective == nil {
+				err = fmt.Errorf("perspective camera without perspective")
+			} else if camera.Perspective.YFov <= 0 || camera.Perspective.ZNear <= 0 {
+				err = fmt.Errorf("yfov and znear must be positive")
+			}
+		case "orthographic":
+			camera.Orthographic = source.Orthographic
+			if camera.Orthographic == nil {
+				err = fmt.Errorf("orthographic camera without orthographic")
+			}
+		default:
+			err = fmt.Errorf("invalid type %q", source.Type)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("camera", i, source.Name), err)
+		}
+
+		l.model.Cameras = append(l.model.Cameras, camera)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package gltf
+
+import (
+	"encoding/json"
+)
+
+// The document types mirror the glTF 2.0 JSON schema. Optional indices are
+// pointers so a missing reference can be told apart from index 0.
+
+type document struct {
+	Asset struct {
+		Version    string `json:"version"`
+		MinVersion string `json:"minVersion"`
+		Generator  string `json:"generator"`
+	} `json:"asset"`
+
+	ExtensionsUsed     []string `json:"extensionsUsed"`
+	ExtensionsRequired []string `json:"extensionsRequired"`
+
+	Scene       *int                 `json:"scene"`
+	Scenes      []documentScene      `json:"scenes"`
+	Nodes       []documentNode       `json:"nodes"`
+	Meshes      []documentMesh       `json:"meshes"`
+	Accessors   []documentAccessor   `json:"accessors"`
+	BufferViews []documentBufferView `json:"bufferViews"`
+	Buffers     []documentBuffer     `json:"buffers"`
+	Materials   []documentMaterial   `json:"materials"`
+	Textures    []documentTexture    `json:"textures"`
+	Images      []documentImage      `json:"images"`
+	Samplers    []documentSampler    `json:"samplers"`
+	Cameras     []documentCamera     `json:"cameras"`
+	Skins       []documentSkin       `json:"skins"`
+	Animations  []documentAnimation  `json:"animations"`
+}
+
+type documentScene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+type documentNode struct {
+	Name        string       `json:"name"`
+	Children    []int        `json:"children"`
+	Mesh        *int         `json:"mesh"`
+	Camera      *int         `json:"camera"`
+	Skin        *int         `json:"skin"`
+	Matrix      *[16]float32 `json:"matrix"`
+	Translation *[3]float32  `json:"translation"`
+	Rotation    *[4]float32  `json:"rotation"`
+	Scale       *[3]float32  `json:"scale"`
+	Weights     []float32    `json:"weights"`
+}
+
+type documentMesh struct {
+	Name       string              `json:"name"`
+	Primitives []documentPrimitive `json:"primitives"`
+	Weights    []float32           `json:"weights"`
+}
+
+type documentPrimitive struct {
+	Attributes map[string]int   `json:"attributes"`
+	Indices    *int             `json:"indices"`
+	Material   *int             `json:"material"`
+	Mode       *int             `json:"mode"`
+	Targets    []map[string]int `json:"targets"`
+}
+
+type documentAccessor struct {
+	Name          string          `json:"name"`
+	BufferView    *int            `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Sparse        *documentSparse `json:"sparse"`
+}
+
+type documentSparse struct {
+	Count   int `json:"count"`
+	Indices struct {
+		BufferView    int `json:"bufferView"`
+		ByteOffset    int `json:"byteOffset"`
+		ComponentType int `json:"componentType"`
+	} `json:"indices"`
+	Values struct {
+		BufferView int `json:"bufferView"`
+		ByteOffset int `json:"byteOffset"`
+	} `json:"values"`
+}
+
+type documentBufferView struct {
+	Name       string `json:"name"`
+	Buffer     int    `json:"buffer"`
+	ByteOffset int    `json:"byteOffset"`
+	ByteLength int    `json:"byteLength"`
+	ByteStride int    `json:"byteStride"`
+	Target     int    `json:"target"`
+}
+
+type documentBuffer struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type documentTextureInfo struct {
+	Index    int      `json:"index"`
+	TexCoord int      `json:"texCoord"`
+	Scale    *float32 `json:"scale"`
+	Strength *float32 `json:"strength"`
+}
+
+type documentMaterial struct {
+	Name                 string `json:"name"`
+	PBRMetallicRoughness *struct {
+		BaseColorFactor          *[4]float32          `json:"baseColorFactor"`
+		BaseColorTexture         *documentTextureInfo `json:"baseColorTexture"`
+		MetallicFactor           *float32             `json:"metallicFactor"`
+		RoughnessFactor          *float32             `json:"roughnessFactor"`
+		MetallicRoughnessTexture *documentTextureInfo `json:"metallicRoughnessTexture"`
+	} `json:"pbrMetallicRoughness"`
+	NormalTexture    *documentTextureInfo `json:"normalTexture"`
+	OcclusionTexture *documentTextureInfo `json:"occlusionTexture"`
+	EmissiveTexture  *documentTextureInfo `json:"emissiveTexture"`
+	EmissiveFactor   [3]float32           `json:"emissiveFactor"`
+	AlphaMode        string               `json:"alphaMode"`
+	AlphaCutoff      *float32             `json:"alphaCutoff"`
+	DoubleSided      bool                 `json:"doubleSided"`
+}
+
+type documentTexture struct {
+	Name    string `json:"name"`
+	Sampler *int   `json:"sampler"`
+	Source  *int   `json:"source"`
+}
+
+type documentImage struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+type documentSampler struct {
+	Name      string `json:"name"`
+	MagFilter int    `json:"magFilter"`
+	MinFilter int    `json:"minFilter"`
+	WrapS     int    `json:"wrapS"`
+	WrapT     int    `json:"wrapT"`
+}
+
+type documentCamera struct {
+	Name         string        `json:"name"`
+	Type         string        `json:"type"`
+	Perspective  *Perspective  `json:"perspective"`
+	Orthographic *Orthographic `json:"orthographic"`
+}
+
+type documentSkin struct {
+	Name                string `json:"name"`
+	InverseBindMatrices *int   `json:"inverseBindMatrices"`
+	Skeleton            *int   `json:"skeleton"`
+	Joints              []int  `json:"joints"`
+}
+
+type documentAnimation struct {
+	Name     string `json:"name"`
+	Channels []struct {
+		Sampler int `json:"sampler"`
+		Target  struct {
+			Node *int   `json:"node"`
+			Path string `json:"path"`
+		} `json:"target"`
+	} `json:"channels"`
+	Samplers []struct {
+		Input         int    `json:"input"`
+		Interpolation string `json:"interpolation"`
+		Output        int    `json:"output"`
+	} `json:"samplers"`
+}
+
+func parseDocument(data []byte) (*document, error) {
+	doc := &document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
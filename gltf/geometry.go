@@ -0,0 +1,150 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/go-gl/example/geometry"
+)
+
+// Geometry converts the primitive to a geometry mesh using POSITION, NORMAL,
+// TEXCOORD_0 and TANGENT, which must have the types the spec gives them.
+// Strips, fans and loops are turned into lists. Missing normals are made
+// flat as the spec asks, which unwelds the vertices, and missing tangents
+// are computed. glTF UVs start at the top of the image, images uploaded
+// first row first line up with them as is.
+func (primitive *Primitive) Geometry() (*geometry.Mesh, error) {
+	positions := primitive.Attributes["POSITION"]
+	if positions == nil {
+		return nil, fmt.Errorf("primitive has no POSITION")
+	}
+
+	for _, attribute := range []struct {
+		semantic, accessorType string
+		// normalized allows normalized unsigned bytes and shorts besides
+		// floats, as the spec does for texture coordinates.
+		normalized bool
+	}{
+		{"POSITION", "VEC3", false},
+		{"NORMAL", "VEC3", false},
+		{"TEXCOORD_0", "VEC2", true},
+		{"TANGENT", "VEC4", false},
+	} {
+		accessor := primitive.Attributes[attribute.semantic]
+		if accessor == nil {
+			continue
+		}
+
+		float := accessor.ComponentType == Float
+		normalized := accessor.Normalized && (accessor.ComponentType == UnsignedByte || accessor.ComponentType == UnsignedShort)
+		if accessor.Type != attribute.accessorType || !(float || attribute.normalized && normalized) {
+			return nil, fmt.Errorf("%s is %s of component type %d, not %s of floats", attribute.semantic, accessor.Type, accessor.ComponentType, attribute.accessorType)
+		}
+	}
+
+	m := &geometry.Mesh{}
+	var err error
+	if m.Primitive, m.Indices, err = primitive.listIndices(positions.Count); err != nil {
+		return nil, err
+	}
+
+	normals := primitive.Attributes["NORMAL"]
+	uvs := primitive.Attributes["TEXCOORD_0"]
+	tangents := primitive.Attributes["TANGENT"]
+
+	m.Vertices = make([]geometry.Vertex, positions.Count)
+	for i := range m.Vertices {
+		m.Vertices[i].Position = positions.Vec3(i)
+		if normals != nil {
+			m.Vertices[i].Normal = normals.Vec3(i)
+		}
+		if uvs != nil {
+			m.Vertices[i].UV = uvs.Vec2(i)
+		}
+		if tangents != nil {
+			m.Vertices[i].Tangent = tangents.Vec4(i)
+		}
+	}
+
+	if normals == nil && m.Primitive == geometry.Triangles {
+		flatten(m)
+	}
+
+	if tangents == nil {
+		m.ComputeTangents()
+	}
+
+	return m, nil
+}
+
+// listIndices returns the primitive's indices as a triangle or line list.
+func (primitive *Primitive) listIndices(vertexCount int) (geometry.Primitive, []uint32, error) {
+	indices := primitive.Indices
+	if indices == nil {
+		indices = make([]uint32, vertexCount)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	// Lists are copied so the mesh doesn't share the primitive's indices
+	var list []uint32
+
+	switch primitive.Mode {
+	case Triangles:
+		return geometry.Triangles, append(list, indices[:len(indices)/3*3]...), nil
+
+	case TriangleStrip:
+		// Every other triangle is flipped to keep the winding
+		for i := 0; i+2 < len(indices); i++ {
+			if i%2 == 0 {
+				list = append(list, indices[i], indices[i+1], indices[i+2])
+			} else {
+				list = append(list, indices[i+1], indices[i], indices[i+2])
+			}
+		}
+		return geometry.Triangles, list, nil
+
+	case TriangleFan:
+		for i := 1; i+1 < len(indices); i++ {
+			list = append(list, indices[0], indices[i], indices[i+1])
+		}
+		return geometry.Triangles, list, nil
+
+	case Lines:
+		return geometry.Lines, append(list, indices[:len(indices)/2*2]...), nil
+
+	case LineStrip, LineLoop:
+		for i := 0; i+1 < len(indices); i++ {
+			list = append(list, indices[i], indices[i+1])
+		}
+		if primitive.Mode == LineLoop && len(indices) > 2 {
+			list = append(list, indices[len(indices)-1], indices[0])
+		}
+		return geometry.Lines, list, nil
+	}
+
+	return 0, nil, fmt.Errorf("mode %d can't be converted", primitive.Mode)
+}
+
+// flatten gives every triangle its own vertices with the face normal.
+func flatten(m *geometry.Mesh) {
+	vertices := make([]geometry.Vertex, len(m.Indices))
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := m.Vertices[m.Indices[i]], m.Vertices[m.Indices[i+1]], m.Vertices[m.Indices[i+2]]
+
+		normal := b.Position.Sub(a.Position).Cross(c.Position.Sub(a.Position))
+		if normal.Len() > 0 {
+			normal = normal.Normalize()
+		}
+
+		for j, vertex := range []geometry.Vertex{a, b, c} {
+			vertex.Normal = normal
+			vertices[i+j] = vertex
+		}
+	}
+
+	m.Vertices = vertices
+	for i := range m.Indices {
+		m.Indices[i] = uint32(i)
+	}
+}
@@ -0,0 +1,170 @@
+// Package gltf reads glTF 2.0 models, both .gltf JSON with external or data
+// URI buffers and .glb binaries.
+//
+// Loading is pure Go. Every accessor is validated and decoded up front, so a
+// Model only holds plain slices and references between objects are indices
+// into the Model's slices, -1 when absent. No extensions are supported, files
+// that require one are rejected.
+package gltf
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+type Model struct {
+	Scenes []Scene
+	// Scene is the scene to show when loading, -1 when the file doesn't say.
+	Scene int
+
+	Nodes      []Node
+	Meshes     []Mesh
+	Materials  []Material
+	Textures   []Texture
+	Images     []Image
+	Samplers   []Sampler
+	Cameras    []Camera
+	Skins      []Skin
+	Animations []Animation
+}
+
+type Scene struct {
+	Name string
+	// Nodes are the root nodes of the scene.
+	Nodes []int
+}
+
+// Load reads a .gltf or .glb file, external buffers and images are looked up
+// relative to it.
+func Load(fsys fs.FS, name string) (*Model, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := parse(data, fsys, path.Dir(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return model, nil
+}
+
+// Parse reads a .gltf or .glb file. Without a file system only the GLB
+// binary chunk and data URIs can be resolved, use Load for external files.
+func Parse(r io.Reader) (*Model, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(data, nil, "")
+}
+
+type loader struct {
+	doc    *document
+	fsys   fs.FS
+	dir    string
+	binary []byte
+
+	buffers   [][]byte
+	accessors []*Accessor
+	model     *Model
+}
+
+func parse(data []byte, fsys fs.FS, dir string) (*Model, error) {
+	l := &loader{fsys: fsys, dir: dir}
+
+	if isGLB(data) {
+		var err error
+		if data, l.binary, err = splitGLB(data); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	l.doc = doc
+
+	// A minimum version says which loaders can read the file, the version
+	// which one it's written for.
+	version := doc.Asset.Version
+	if doc.Asset.MinVersion != "" {
+		version = doc.Asset.MinVersion
+	}
+	if !strings.HasPrefix(version, "2.") {
+		return nil, fmt.Errorf("unsupported version %q", version)
+	}
+
+	if len(doc.ExtensionsRequired) > 0 {
+		return nil, fmt.Errorf("required extension %s isn't supported", doc.ExtensionsRequired[0])
+	}
+
+	if err := l.loadBuffers(); err != nil {
+		return nil, err
+	}
+
+	l.accessors = make([]*Accessor, len(doc.Accessors))
+	l.model = &Model{Scene: -1}
+
+	for _, load := range []func() error{
+		l.loadSamplers,
+		l.loadImages,
+		l.loadTextures,
+		l.loadMaterials,
+		l.loadMeshes,
+		l.loadCameras,
+		l.loadNodes,
+		l.loadSkins,
+		l.loadAnimations,
+		l.loadScenes,
+	} {
+		if err := load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.model, nil
+}
+
+// reference resolves an optional index into a list of count objects, -1 when
+// absent.
+func reference(index *int, count int, kind string) (int, error) {
+	if index == nil {
+		return -1, nil
+	}
+
+	if *index < 0 || *index >= count {
+		return 0, fmt.Errorf("%s %d doesn't exist", kind, *index)
+	}
+
+	return *index, nil
+}
+
+func (l *loader) loadScenes() error {
+	for i, source := range l.doc.Scenes {
+		for _, node := range source.Nodes {
+			if node < 0 || node >= len(l.model.Nodes) {
+				return fmt.Errorf("%s: node %d doesn't exist", describe("scene", i, source.Name), node)
+			}
+			if l.model.Nodes[node].Parent >= 0 {
+				return fmt.Errorf("%s: node %d isn't a root node", describe("scene", i, source.Name), node)
+			}
+		}
+
+		l.model.Scenes = append(l.model.Scenes, Scene{Name: source.Name, Nodes: source.Nodes})
+	}
+
+	scene, err := reference(l.doc.Scene, len(l.model.Scenes), "scene")
+	if err != nil {
+		return err
+	}
+	l.model.Scene = scene
+
+	return nil
+}
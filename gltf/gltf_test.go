@@ -0,0 +1,393 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// triangleBuffer holds one triangle: 3 positions, 3 normals, 3 UVs and 3
+// unsigned short indices padded to 4 bytes, 104 bytes in all.
+func triangleBuffer() []byte {
+	var data []byte
+	for _, value := range []float32{
+		0, 0, 0, 1, 0, 0, 0, 1, 0,
+		0, 0, 1, 0, 0, 1, 0, 0, 1,
+		0, 0, 1, 0, 0, 1,
+	} {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(value))
+	}
+
+	for _, index := range []uint16{0, 1, 2, 0} {
+		data = binary.LittleEndian.AppendUint16(data, index)
+	}
+
+	return data
+}
+
+// triangle returns the document drawing triangleBuffer, its buffer read from
+// uri or the GLB binary chunk when uri is empty.
+func triangle(uri string) map[string]interface{} {
+	buffer := map[string]interface{}{"byteLength": 104}
+	if uri != "" {
+		buffer["uri"] = uri
+	}
+
+	return map[string]interface{}{
+		"asset":  map[string]interface{}{"version": "2.0"},
+		"scene":  0,
+		"scenes": []interface{}{map[string]interface{}{"nodes": []int{0}}},
+		"nodes": []interface{}{map[string]interface{}{
+			"name": "root", "mesh": 0, "translation": []float32{1, 2, 3},
+		}},
+		"meshes": []interface{}{map[string]interface{}{
+			"name": "triangle",
+			"primitives": []interface{}{map[string]interface{}{
+				"attributes": map[string]int{"POSITION": 0, "NORMAL": 1, "TEXCOORD_0": 2},
+				"indices":    3,
+			}},
+		}},
+		"accessors": []interface{}{
+			map[string]interface{}{"bufferView": 0, "componentType": Float, "count": 3, "type": "VEC3"},
+			map[string]interface{}{"bufferView": 0, "byteOffset": 36, "componentType": Float, "count": 3, "type": "VEC3"},
+			map[string]interface{}{"bufferView": 1, "componentType": Float, "count": 3, "type": "VEC2"},
+			map[string]interface{}{"bufferView": 2, "componentType": UnsignedShort, "count": 3, "type": "SCALAR"},
+		},
+		"bufferViews": []interface{}{
+			map[string]interface{}{"buffer": 0, "byteLength": 72},
+			map[string]interface{}{"buffer": 0, "byteOffset": 72, "byteLength": 24},
+			map[string]interface{}{"buffer": 0, "byteOffset": 96, "byteLength": 6},
+		},
+		"buffers": []interface{}{buffer},
+	}
+}
+
+func dataURI(data []byte) string {
+	return "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func marshal(t testing.TB, doc map[string]interface{}) []byte {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// glb packs a JSON and an optional binary chunk, both padded to 4 bytes.
+func glb(jsonChunk []byte, binaryChunk []byte) []byte {
+	pad := func(data []byte, with byte) []byte {
+		for len(data)%4 != 0 {
+			data = append(data, with)
+		}
+		return data
+	}
+
+	body := binary.LittleEndian.AppendUint32(nil, uint32(len(pad(jsonChunk, ' '))))
+	body = binary.LittleEndian.AppendUint32(body, glbChunkJSON)
+	body = append(body, pad(jsonChunk, ' ')...)
+
+	if binaryChunk != nil {
+		body = binary.LittleEndian.AppendUint32(body, uint32(len(pad(binaryChunk, 0))))
+		body = binary.LittleEndian.AppendUint32(body, glbChunkBIN)
+		body = append(body, pad(binaryChunk, 0)...)
+	}
+
+	header := binary.LittleEndian.AppendUint32(nil, glbMagic)
+	header = binary.LittleEndian.AppendUint32(header, 2)
+	header = binary.LittleEndian.AppendUint32(header, uint32(12+len(body)))
+
+	return append(header, body...)
+}
+
+// checkTriangle compares a model loaded from triangle to what it holds.
+func checkTriangle(t *testing.T, model *Model) {
+	t.Helper()
+
+	if model.Scene != 0 || len(model.Scenes) != 1 || len(model.Nodes) != 1 || len(model.Meshes) != 1 {
+		t.Fatalf("scene %d, %d scenes, %d nodes, %d meshes", model.Scene, len(model.Scenes), len(model.Nodes), len(model.Meshes))
+	}
+
+	if node := model.Nodes[0]; node.Mesh != 0 || node.Parent != -1 || model.WorldTransform(0) != mgl32.Translate3D(1, 2, 3) {
+		t.Errorf("node %+v", node)
+	}
+
+	primitive := &model.Meshes[0].Primitives[0]
+	if primitive.Mode != Triangles || primitive.Material != -1 || len(primitive.Indices) != 3 {
+		t.Fatalf("primitive mode %d, material %d, indices %v", primitive.Mode, primitive.Material, primitive.Indices)
+	}
+
+	m, err := primitive.Geometry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Vertices) != 3 || len(m.Indices) != 3 {
+		t.Fatalf("%d vertices, %d indices", len(m.Vertices), len(m.Indices))
+	}
+
+	if m.Vertices[1].Position != (mgl32.Vec3{1, 0, 0}) || m.Vertices[2].Normal != (mgl32.Vec3{0, 0, 1}) || m.Vertices[2].UV != (mgl32.Vec2{0, 1}) {
+		t.Errorf("vertices %+v", m.Vertices)
+	}
+}
+
+func TestParse(t *testing.T) {
+	model, err := Parse(bytes.NewReader(marshal(t, triangle(dataURI(triangleBuffer())))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTriangle(t, model)
+}
+
+func TestParseGLB(t *testing.T) {
+	model, err := Parse(bytes.NewReader(glb(marshal(t, triangle("")), triangleBuffer())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTriangle(t, model)
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"models/triangle.gltf":            {Data: marshal(t, triangle("data/triangle%20buffer.bin"))},
+		"models/data/triangle buffer.bin": {Data: triangleBuffer()},
+	}
+
+	model, err := Load(fsys, "models/triangle.gltf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkTriangle(t, model)
+
+	if _, err := Parse(bytes.NewReader(fsys["models/triangle.gltf"].Data)); err == nil {
+		t.Errorf("Parse resolved an external URI")
+	}
+}
+
+func TestSparse(t *testing.T) {
+	// Replaces the second position with the third normal
+	data := append(triangleBuffer(), 1, 0, 0, 0)
+	doc := triangle(dataURI(data))
+	doc["buffers"].([]interface{})[0].(map[string]interface{})["byteLength"] = 108
+	doc["bufferViews"] = append(doc["bufferViews"].([]interface{}),
+		map[string]interface{}{"buffer": 0, "byteOffset": 104, "byteLength": 4},
+		map[string]interface{}{"buffer": 0, "byteOffset": 60, "byteLength": 12},
+	)
+	doc["accessors"].([]interface{})[0].(map[string]interface{})["sparse"] = map[string]interface{}{
+		"count":   1,
+		"indices": map[string]interface{}{"bufferView": 3, "componentType": UnsignedInt},
+		"values":  map[string]interface{}{"bufferView": 4},
+	}
+
+	model, err := Parse(bytes.NewReader(marshal(t, doc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := model.Meshes[0].Primitives[0].Attributes["POSITION"].Vec3(1); got != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("sparse position is %v", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(doc map[string]interface{})
+		want   string
+	}{
+		{"version", func(doc map[string]interface{}) {
+			doc["asset"] = map[string]interface{}{"version": "1.0"}
+		}, "unsupported version"},
+		{"required extension", func(doc map[string]interface{}) {
+			doc["extensionsRequired"] = []string{"KHR_draco_mesh_compression"}
+		}, "required extension"},
+		{"short buffer", func(doc map[string]interface{}) {
+			doc["buffers"].([]interface{})[0].(map[string]interface{})["byteLength"] = 200
+		}, "byte length is 200"},
+		{"view out of range", func(doc map[string]interface{}) {
+			doc["bufferViews"].([]interface{})[1].(map[string]interface{})["byteOffset"] = 90
+		}, "out of range of buffer 0"},
+		{"accessor past its view", func(doc map[string]interface{}) {
+			doc["accessors"].([]interface{})[1].(map[string]interface{})["count"] = 4
+		}, "don't fit in buffer view 0"},
+		{"missing accessor", func(doc map[string]interface{}) {
+			doc["meshes"].([]interface{})[0].(map[string]interface{})["primitives"].([]interface{})[0].(map[string]interface{})["indices"] = 9
+		}, "accessor 9 doesn't exist"},
+		{"index out of range", func(doc map[string]interface{}) {
+			doc["accessors"].([]interface{})[0].(map[string]interface{})["count"] = 2
+			doc["accessors"].([]interface{})[1].(map[string]interface{})["count"] = 2
+			doc["accessors"].([]interface{})[2].(map[string]interface{})["count"] = 2
+		}, "index 2 out of range of 2 vertices"},
+		{"different counts", func(doc map[string]interface{}) {
+			doc["accessors"].([]interface{})[2].(map[string]interface{})["count"] = 2
+		}, "different counts"},
+		{"non root scene node", func(doc map[string]interface{}) {
+			doc["nodes"] = []interface{}{map[string]interface{}{"children": []int{1}}, map[string]interface{}{"mesh": 0}}
+			doc["scenes"] = []interface{}{map[string]interface{}{"nodes": []int{1}}}
+		}, "isn't a root node"},
+		{"missing scene", func(doc map[string]interface{}) {
+			doc["scene"] = 1
+		}, "scene 1 doesn't exist"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := triangle(dataURI(triangleBuffer()))
+			test.change(doc)
+
+			_, err := Parse(bytes.NewReader(marshal(t, doc)))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseGLBErrors(t *testing.T) {
+	valid := glb(marshal(t, triangle("")), triangleBuffer())
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated header", valid[:10]},
+		{"length past the end", valid[:len(valid)-4]},
+		{"truncated chunk", append(valid[:12:12], 100, 0, 0, 0)},
+		{"no binary chunk", glb(marshal(t, triangle("")), nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse(bytes.NewReader(test.data)); err == nil {
+				t.Errorf("Parse succeeded")
+			}
+		})
+	}
+}
+
+func TestGeometryTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		accessor int
+		change   map[string]interface{}
+	}{
+		{"VEC2 positions", 0, map[string]interface{}{"type": "VEC2"}},
+		{"scalar normals", 1, map[string]interface{}{"type": "SCALAR"}},
+		{"integer positions", 0, map[string]interface{}{"componentType": UnsignedInt, "count": 1}},
+		{"VEC3 texture coordinates", 2, map[string]interface{}{"type": "VEC3", "count": 2}},
+		{"unnormalized texture coordinates", 2, map[string]interface{}{"componentType": UnsignedShort}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := triangle(dataURI(triangleBuffer()))
+			accessor := doc["accessors"].([]interface{})[test.accessor].(map[string]interface{})
+			for key, value := range test.change {
+				accessor[key] = value
+			}
+
+			// Counts have to match for the model to load at all
+			count := accessor["count"]
+			for _, other := range doc["accessors"].([]interface{})[:3] {
+				other.(map[string]interface{})["count"] = count
+			}
+			if count != 3 {
+				delete(doc["meshes"].([]interface{})[0].(map[string]interface{})["primitives"].([]interface{})[0].(map[string]interface{}), "indices")
+			}
+
+			model, err := Parse(bytes.NewReader(marshal(t, doc)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := model.Meshes[0].Primitives[0].Geometry(); err == nil {
+				t.Errorf("Geometry succeeded")
+			}
+		})
+	}
+
+	normalized := triangle(dataURI(triangleBuffer()))
+	uvs := normalized["accessors"].([]interface{})[2].(map[string]interface{})
+	uvs["componentType"], uvs["normalized"], uvs["count"] = UnsignedShort, true, 3
+
+	model, err := Parse(bytes.NewReader(marshal(t, normalized)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.Meshes[0].Primitives[0].Geometry(); err != nil {
+		t.Errorf("normalized texture coordinates: %v", err)
+	}
+}
+
+func TestNewMeshIndex(t *testing.T) {
+	model, err := Parse(bytes.NewReader(marshal(t, triangle(dataURI(triangleBuffer())))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Checked before any GL call
+	for _, index := range []int{-1, 1} {
+		if _, err := model.NewMesh(index); err == nil {
+			t.Errorf("NewMesh(%d) succeeded", index)
+		}
+	}
+}
+
+func FuzzLoad(f *testing.F) {
+	f.Add(marshal(f, triangle(dataURI(triangleBuffer()))))
+	f.Add(marshal(f, triangle("triangle.bin")))
+	f.Add(glb(marshal(f, triangle("")), triangleBuffer()))
+
+	strip := triangle("triangle.bin")
+	strip["meshes"].([]interface{})[0].(map[string]interface{})["primitives"].([]interface{})[0].(map[string]interface{})["mode"] = TriangleStrip
+	f.Add(marshal(f, strip))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fsys := fstest.MapFS{
+			"model.gltf":   {Data: data},
+			"triangle.bin": {Data: triangleBuffer()},
+		}
+
+		model, err := Load(fsys, "model.gltf")
+		if err != nil {
+			return
+		}
+
+		for i := range model.Nodes {
+			model.WorldTransform(i)
+		}
+
+		for _, mesh := range model.Meshes {
+			for _, primitive := range mesh.Primitives {
+				for semantic, accessor := range primitive.Attributes {
+					if len(accessor.Floats) != accessor.Count*accessor.Components() {
+						t.Fatalf("%s has %d values for %d elements", semantic, len(accessor.Floats), accessor.Count)
+					}
+				}
+
+				m, err := primitive.Geometry()
+				if err != nil {
+					continue
+				}
+
+				for _, index := range m.Indices {
+					if int(index) >= len(m.Vertices) {
+						t.Fatalf("index %d out of range of %d vertices", index, len(m.Vertices))
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type AlphaMode string
+
+const (
+	Opaque AlphaMode = "OPAQUE"
+	Mask   AlphaMode = "MASK"
+	Blend  AlphaMode = "BLEND"
+)
+
+// Material is a metallic-roughness PBR material, missing values get the
+// defaults from the spec.
+type Material struct {
+	Name string
+
+	BaseColorFactor          mgl32.Vec4
+	BaseColorTexture         *TextureInfo
+	MetallicFactor           float32
+	RoughnessFactor          float32
+	MetallicRoughnessTexture *TextureInfo
+
+	NormalTexture    *TextureInfo
+	OcclusionTexture *TextureInfo
+	EmissiveTexture  *TextureInfo
+	EmissiveFactor   mgl32.Vec3
+
+	AlphaMode AlphaMode
+	// AlphaCutoff only applies to Mask.
+	AlphaCutoff float32
+	DoubleSided bool
+}
+
+// TextureInfo is a reference from a material to a texture.
+type TextureInfo struct {
+	Texture  int
+	TexCoord int
+	// Scale is the normal scale of normal textures and the strength of
+	// occlusion textures, 1 for the others.
+	Scale float32
+}
+
+type Texture struct {
+	Name string
+	// Sampler is -1 for repeat wrapping with filters up to the renderer.
+	Sampler int
+	// Source is the image, -1 when an extension would provide it.
+	Source int
+}
+
+// Image is encoded image data, PNG or JPEG as far as the core spec goes.
+// Data is read from the buffer view, data URI or file, URI is kept for
+// loaders that want to cache by path.
+type Image struct {
+	Name     string
+	URI      string
+	MimeType string
+	Data     []byte
+}
+
+// Sampler filters and wrap modes are GL enums, filters are 0 when the file
+// leaves them up to the renderer.
+type Sampler struct {
+	Name      string
+	MagFilter int32
+	MinFilter int32
+	WrapS     int32
+	WrapT     int32
+}
+
+// glRepeat is GL_REPEAT, the default wrap mode.
+const glRepeat = 10497
+
+func (l *loader) loadSamplers() error {
+	for _, source := range l.doc.Samplers {
+		sampler := Sampler{
+			Name:      source.Name,
+			MagFilter: int32(source.MagFilter),
+			MinFilter: int32(source.MinFilter),
+			WrapS:     int32(source.WrapS),
+			WrapT:     int32(source.WrapT),
+		}
+
+		if sampler.WrapS == 0 {
+			sampler.WrapS = glRepeat
+		}
+		if sampler.WrapT == 0 {
+			sampler.WrapT = glRepeat
+		}
+
+		l.model.Samplers = append(l.model.Samplers, sampler)
+	}
+
+	return nil
+}
+
+func (l *loader) loadImages() error {
+	for i, source := range l.doc.Images {
+		image := Image{Name: source.Name, URI: source.URI, MimeType: source.MimeType}
+
+		var err error
+		switch {
+		case source.BufferView != nil:
+			var view bufferView
+			if view, err = l.bufferView(*source.BufferView); err == nil {
+				image.Data = view.data
+			}
+			if source.MimeType == "" {
+				err = fmt.Errorf("mime type is required with a buffer view")
+			}
+		case source.URI != "":
+			image.Data, err = l.readURI(source.URI)
+		default:
+			err = fmt.Errorf("no URI and no buffer view")
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("image", i, source.Name), err)
+		}
+
+		l.model.Images = append(l.model.Images, image)
+	}
+
+	return nil
+}
+
+func (l *loader) loadTextures() error {
+	for i, source := range l.doc.Textures {
+		texture := Texture{Name: source.Name}
+
+		var err error
+		if texture.Sampler, err = reference(source.Sampler, len(l.model.Samplers), "sampler"); err == nil {
+			texture.Source, err = reference(source.Source, len(l.model.Images), "image")
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("texture", i, source.Name), err)
+		}
+
+		l.model.Textures = append(l.model.Textures, texture)
+	}
+
+	return nil
+}
+
+func (l *loader) loadMaterials() error {
+	for i, source := range l.doc.Materials {
+		material, err := l.material(source)
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("material", i, source.Name), err)
+		}
+
+		l.model.Materials = append(l.model.Materials, material)
+	}
+
+	return nil
+}
+
+func (l *loader) material(source documentMaterial) (Material, error) {
+	material := Material{
+		Name:            source.Name,
+		BaseColorFactor: mgl32.Vec4{1, 1, 1, 1},
+		MetallicFactor:  1,
+		RoughnessFactor: 1,
+		EmissiveFactor:  source.EmissiveFactor,
+		AlphaMode:       Opaque,
+		AlphaCutoff:     0.5,
+		DoubleSided:     source.DoubleSided,
+	}
+
+	var err error
+
+	if pbr := source.PBRMetallicRoughness; pbr != nil {
+		if pbr.BaseColorFactor != nil {
+			material.BaseColorFactor = *pbr.BaseColorFactor
+		}
+		if pbr.MetallicFactor != nil {
+			material.MetallicFactor = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			material.RoughnessFactor = *pbr.RoughnessFactor
+		}
+
+		if material.BaseColorTexture, err = l.textureInfo(pbr.BaseColorTexture, nil); err != nil {
+			return Material{}, fmt.Errorf("base color texture: %v", err)
+		}
+		if material.MetallicRoughnessTexture, err = l.textureInfo(pbr.MetallicRoughnessTexture, nil); err != nil {
+			return Material{}, fmt.Errorf("metallic roughness texture: %v", err)
+		}
+	}
+
+	if source.NormalTexture != nil {
+		if material.NormalTexture, err = l.textureInfo(source.NormalTexture, source.NormalTexture.Scale); err != nil {
+			return Material{}, fmt.Errorf("normal texture: %v", err)
+		}
+	}
+	if source.OcclusionTexture != nil {
+		if material.OcclusionTexture, err = l.textureInfo(source.OcclusionTexture, source.OcclusionTexture.Strength); err != nil {
+			return Material{}, fmt.Errorf("occlusion texture: %v", err)
+		}
+	}
+	if material.EmissiveTexture, err = l.textureInfo(source.EmissiveTexture, nil); err != nil {
+		return Material{}, fmt.Errorf("emissive texture: %v", err)
+	}
+
+	switch mode := AlphaMode(source.AlphaMode); mode {
+	case "":
+	case Opaque, Mask, Blend:
+		material.AlphaMode = mode
+	default:
+		return Material{}, fmt.Errorf("invalid alpha mode %q", source.AlphaMode)
+	}
+
+	if source.AlphaCutoff != nil {
+		material.AlphaCutoff = *source.AlphaCutoff
+	}
+
+	return material, nil
+}
+
+func (l *loader) textureInfo(source *documentTextureInfo, scale *float32) (*TextureInfo, error) {
+	if source == nil {
+		return nil, nil
+	}
+
+	if source.Index < 0 || source.Index >= len(l.model.Textures) {
+		return nil, fmt.Errorf("texture %d doesn't exist", source.Index)
+	}
+
+	info := &TextureInfo{Texture: source.Index, TexCoord: source.TexCoord, Scale: 1}
+	if scale != nil {
+		info.Scale = *scale
+	}
+
+	return info, nil
+}
@@ -0,0 +1,134 @@
+package gltf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Primitive modes, the GL enums of the same name.
+const (
+	Points        = 0
+	Lines         = 1
+	LineLoop      = 2
+	LineStrip     = 3
+	Triangles     = 4
+	TriangleStrip = 5
+	TriangleFan   = 6
+)
+
+type Mesh struct {
+	Name       string
+	Primitives []Primitive
+	// Weights are the default morph target weights.
+	Weights []float32
+}
+
+type Primitive struct {
+	// Mode is one of the primitive modes, which can be passed to GL as is.
+	Mode int
+	// Attributes by semantic, POSITION, NORMAL, TEXCOORD_0, JOINTS_0 and so
+	// on.
+	Attributes map[string]*Accessor
+	// Indices is nil for non indexed primitives.
+	Indices  []uint32
+	Material int
+	// Targets are the morph targets, attribute displacements by semantic.
+	Targets []map[string]*Accessor
+}
+
+func (l *loader) loadMeshes() error {
+	for i, source := range l.doc.Meshes {
+		mesh := Mesh{Name: source.Name, Weights: source.Weights}
+
+		for j, primitiveSource := range source.Primitives {
+			primitive, err := l.primitive(primitiveSource)
+			if err != nil {
+				return fmt.Errorf("%s: primitive %d: %v", describe("mesh", i, source.Name), j, err)
+			}
+			mesh.Primitives = append(mesh.Primitives, primitive)
+		}
+
+		l.model.Meshes = append(l.model.Meshes, mesh)
+	}
+
+	return nil
+}
+
+func (l *loader) primitive(source documentPrimitive) (Primitive, error) {
+	primitive := Primitive{Mode: Triangles}
+
+	if source.Mode != nil {
+		if *source.Mode < Points || *source.Mode > TriangleFan {
+			return Primitive{}, fmt.Errorf("invalid mode %d", *source.Mode)
+		}
+		primitive.Mode = *source.Mode
+	}
+
+	var err error
+	if primitive.Material, err = reference(source.Material, len(l.model.Materials), "material"); err != nil {
+		return Primitive{}, err
+	}
+
+	if primitive.Attributes, err = l.attributes(source.Attributes); err != nil {
+		return Primitive{}, err
+	}
+
+	count := -1
+	for _, accessor := range primitive.Attributes {
+		if count >= 0 && accessor.Count != count {
+			return Primitive{}, fmt.Errorf("attributes have different counts, %d and %d", count, accessor.Count)
+		}
+		count = accessor.Count
+	}
+
+	for i, target := range source.Targets {
+		attributes, err := l.attributes(target)
+		if err != nil {
+			return Primitive{}, fmt.Errorf("target %d: %v", i, err)
+		}
+		primitive.Targets = append(primitive.Targets, attributes)
+	}
+
+	if source.Indices != nil {
+		indices, err := l.decodeAccessor(*source.Indices)
+		if err != nil {
+			return Primitive{}, err
+		}
+
+		if indices.Type != "SCALAR" || indices.Uints == nil || indices.Normalized {
+			return Primitive{}, fmt.Errorf("%s: indices must be unsigned integer scalars",
+				describe("accessor", *source.Indices, indices.Name))
+		}
+
+		for _, index := range indices.Uints {
+			if count >= 0 && int(index) >= count {
+				return Primitive{}, fmt.Errorf("%s: index %d out of range of %d vertices",
+					describe("accessor", *source.Indices, indices.Name), index, count)
+			}
+		}
+
+		primitive.Indices = indices.Uints
+	}
+
+	return primitive, nil
+}
+
+func (l *loader) attributes(source map[string]int) (map[string]*Accessor, error) {
+	// Decode in a stable order so errors don't depend on map iteration
+	semantics := make([]string, 0, len(source))
+	for semantic := range source {
+		semantics = append(semantics, semantic)
+	}
+	sort.Strings(semantics)
+
+	attributes := map[string]*Accessor{}
+	for _, semantic := range semantics {
+		accessor, err := l.decodeAccessor(source[semantic])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", semantic, err)
+		}
+		attributes[semantic] = accessor
+	}
+
+	return attributes, nil
+}
@@ -0,0 +1,128 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type Node struct {
+	Name     string
+	Parent   int
+	Children []int
+
+	Mesh   int
+	Camera int
+	Skin   int
+
+	// Matrix is the local transform when the file gives one, nil when it's
+	// made of Translation, Rotation and Scale. Animated nodes always use the
+	// latter.
+	Matrix      *mgl32.Mat4
+	Translation mgl32.Vec3
+	Rotation    mgl32.Quat
+	Scale       mgl32.Vec3
+
+	// Weights of the mesh's morph targets, overriding the mesh's.
+	Weights []float32
+}
+
+// LocalTransform is the node's transform relative to its parent.
+func (node *Node) LocalTransform() mgl32.Mat4 {
+	if node.Matrix != nil {
+		return *node.Matrix
+	}
+
+	translation := mgl32.Translate3D(node.Translation.X(), node.Translation.Y(), node.Translation.Z())
+	scale := mgl32.Scale3D(node.Scale.X(), node.Scale.Y(), node.Scale.Z())
+
+	return translation.Mul4(node.Rotation.Mat4()).Mul4(scale)
+}
+
+// WorldTransform multiplies the local transforms from the root down to node.
+func (model *Model) WorldTransform(node int) mgl32.Mat4 {
+	transform := mgl32.Ident4()
+	for ; node >= 0; node = model.Nodes[node].Parent {
+		transform = model.Nodes[node].LocalTransform().Mul4(transform)
+	}
+
+	return transform
+}
+
+func (l *loader) loadNodes() error {
+	count := len(l.doc.Nodes)
+	l.model.Nodes = make([]Node, count)
+
+	for i, source := range l.doc.Nodes {
+		node, err := l.node(source)
+		if err != nil {
+			return fmt.Errorf("%s: %v", describe("node", i, source.Name), err)
+		}
+		l.model.Nodes[i] = node
+	}
+
+	// Parents are only known once all children lists are read
+	for i, source := range l.doc.Nodes {
+		for _, child := range source.Children {
+			if child < 0 || child >= count {
+				return fmt.Errorf("%s: child %d doesn't exist", describe("node", i, source.Name), child)
+			}
+			if l.model.Nodes[child].Parent >= 0 {
+				return fmt.Errorf("%s: child %d already has parent %d", describe("node", i, source.Name), child, l.model.Nodes[child].Parent)
+			}
+			l.model.Nodes[child].Parent = i
+		}
+	}
+
+	// With one parent per node, a cycle is the only way to never reach a root
+	for i := range l.model.Nodes {
+		steps := 0
+		for node := i; node >= 0; node = l.model.Nodes[node].Parent {
+			if steps++; steps > count {
+				return fmt.Errorf("%s: hierarchy has a cycle", describe("node", i, l.model.Nodes[i].Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *loader) node(source documentNode) (Node, error) {
+	node := Node{
+		Name:     source.Name,
+		Parent:   -1,
+		Children: source.Children,
+		Rotation: mgl32.QuatIdent(),
+		Scale:    mgl32.Vec3{1, 1, 1},
+		Weights:  source.Weights,
+	}
+
+	var err error
+	if node.Mesh, err = reference(source.Mesh, len(l.model.Meshes), "mesh"); err != nil {
+		return Node{}, err
+	}
+	if node.Camera, err = reference(source.Camera, len(l.model.Cameras), "camera"); err != nil {
+		return Node{}, err
+	}
+	if node.Skin, err = reference(source.Skin, len(l.doc.Skins), "skin"); err != nil {
+		return Node{}, err
+	}
+
+	if source.Matrix != nil {
+		matrix := mgl32.Mat4(*source.Matrix)
+		node.Matrix = &matrix
+	}
+	if source.Translation != nil {
+		node.Translation = *source.Translation
+	}
+	if source.Rotation != nil {
+		// glTF stores quaternions as x, y, z, w
+		rotation := *source.Rotation
+		node.Rotation = mgl32.Quat{W: rotation[3], V: mgl32.Vec3{rotation[0], rotation[1], rotation[2]}}
+	}
+	if source.Scale != nil {
+		node.Scale = *source.Scale
+	}
+
+	return node, nil
+}
@@ -0,0 +1,67 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/go-gl/example/geometry"
+	"github.com/go-gl/example/mesh"
+	"github.com/go-gl/example/utils"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// NewMesh uploads all primitives of the mesh to one GL mesh with a sub mesh
+// per primitive, named after the primitive's material. Primitives must all be
+// triangles or all be lines. Needs a current GL context.
+func (model *Model) NewMesh(index int) (*mesh.Mesh, error) {
+	if index < 0 || index >= len(model.Meshes) {
+		return nil, fmt.Errorf("mesh %d doesn't exist", index)
+	}
+
+	source := &model.Meshes[index]
+	combined := &geometry.Mesh{}
+	var subMeshes []mesh.SubMesh
+
+	for i := range source.Primitives {
+		primitive := &source.Primitives[i]
+
+		m, err := primitive.Geometry()
+		if err != nil {
+			return nil, fmt.Errorf("%s: primitive %d: %v", describe("mesh", index, source.Name), i, err)
+		}
+
+		if i > 0 && m.Primitive != combined.Primitive {
+			return nil, fmt.Errorf("%s: primitive %d: mixes lines and triangles", describe("mesh", index, source.Name), i)
+		}
+		combined.Primitive = m.Primitive
+
+		name := ""
+		if primitive.Material >= 0 {
+			name = model.Materials[primitive.Material].Name
+		}
+
+		// Indices are made absolute so Draw covers every primitive, sub
+		// meshes need no base vertex then.
+		subMeshes = append(subMeshes, mesh.SubMesh{
+			Name:       name,
+			FirstIndex: len(combined.Indices),
+			IndexCount: len(m.Indices),
+		})
+
+		base := uint32(len(combined.Vertices))
+		combined.Vertices = append(combined.Vertices, m.Vertices...)
+		for _, vertex := range m.Indices {
+			combined.Indices = append(combined.Indices, base+vertex)
+		}
+	}
+
+	result := mesh.New(combined.Vertices, combined.Indices, utils.MustLayoutOf(geometry.Vertex{}))
+	if combined.Primitive == geometry.Lines {
+		result.Mode = gl.LINES
+	}
+	result.SubMeshes = subMeshes
+	if source.Name != "" {
+		result.Label(source.Name)
+	}
+
+	return result, nil
+}
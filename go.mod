@@ -1,10 +1,10 @@
 module github.com/go-gl/example
 
-go 1.14
+go 1.21
 
 require (
 	github.com/go-gl/gl v0.0.0-20210426225639-a3bfa832c8aa
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20210410170116-ea3d685f79fb
 	github.com/go-gl/mathgl v1.0.0
-	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb // indirect
+	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb
 )
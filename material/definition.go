@@ -0,0 +1,128 @@
+package material
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Definition describes a material in JSON, such as:
+//
+//	{
+//	    "vertex": "shaders/lit.vert",
+//	    "fragment": "shaders/lit.frag",
+//	    "uniforms": {"tint": [1, 0.8, 0.6, 1], "shininess": 32, "lit": true},
+//	    "textures": {
+//	        "albedo": {"path": "images/brick.png", "srgb": true, "mipmaps": true},
+//	        "mask": "images/mask.png"
+//	    }
+//	}
+//
+// Numbers are float uniforms, arrays of 2 to 4 numbers vectors. Integer
+// uniforms other than samplers have to be set in code. Textures can be a
+// bare path, loaded linear without mipmaps.
+type Definition struct {
+	Vertex   string                       `json:"vertex"`
+	Fragment string                       `json:"fragment"`
+	Uniforms map[string]interface{}       `json:"uniforms"`
+	Textures map[string]TextureDefinition `json:"textures"`
+}
+
+type TextureDefinition struct {
+	Path    string `json:"path"`
+	SRGB    bool   `json:"srgb"`
+	Mipmaps bool   `json:"mipmaps"`
+	FlipY   bool   `json:"flipY"`
+	// Repeat wraps the texture instead of clamping it to its edges.
+	Repeat bool `json:"repeat"`
+}
+
+// UnmarshalJSON accepts a path string as well as an object.
+func (definition *TextureDefinition) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*definition = TextureDefinition{Path: path}
+		return nil
+	}
+
+	// Another type without the method, not to recurse
+	type fields TextureDefinition
+	return json.Unmarshal(data, (*fields)(definition))
+}
+
+// Options are the texture options the definition asks for.
+func (definition TextureDefinition) Options() texture.Options {
+	options := texture.Options{Mipmaps: definition.Mipmaps, FlipY: definition.FlipY}
+	if definition.SRGB {
+		options.ColorSpace = texture.SRGB
+	}
+	if definition.Repeat {
+		options.WrapS, options.WrapT = gl.REPEAT, gl.REPEAT
+	}
+
+	return options
+}
+
+// ReadDefinition decodes a JSON material definition and checks its uniforms
+// have supported types.
+func ReadDefinition(r io.Reader) (*Definition, error) {
+	var definition Definition
+	if err := json.NewDecoder(r).Decode(&definition); err != nil {
+		return nil, fmt.Errorf("material: %v", err)
+	}
+
+	if definition.Vertex == "" || definition.Fragment == "" {
+		return nil, fmt.Errorf("material: vertex and fragment shaders are required")
+	}
+
+	for _, name := range sortedKeys(definition.Uniforms) {
+		converted, err := convert(jsonValue(definition.Uniforms[name]))
+		if err != nil {
+			return nil, fmt.Errorf("material: %s: %v", name, err)
+		}
+		definition.Uniforms[name] = converted
+	}
+
+	for name, slot := range definition.Textures {
+		if slot.Path == "" {
+			return nil, fmt.Errorf("material: texture %s has no path", name)
+		}
+	}
+
+	return &definition, nil
+}
+
+// jsonValue turns arrays decoded as []interface{} into []float64 when all
+// their elements are numbers.
+func jsonValue(value interface{}) interface{} {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	numbers := make([]float64, len(elements))
+	for i, element := range elements {
+		number, ok := element.(float64)
+		if !ok {
+			return value
+		}
+		numbers[i] = number
+	}
+
+	return numbers
+}
+
+// sortedKeys makes errors the same from one run to the next.
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,223 @@
+// Package material groups a shader with the uniform values and textures it
+// draws with.
+//
+// Materials remember what they last set: uniforms are only set again when
+// their value differs from the one last set on the shader, and textures only
+// bound when another is bound to their unit, so drawing many objects with
+// the same or similar materials costs few GL calls. The state is shared by
+// all materials and forgotten at the start of each frame, code binding
+// textures or using shaders on its own in between materials should call
+// Invalidate afterwards.
+package material
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/example/window"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type Material struct {
+	Name   string
+	Shader shader.Shader
+
+	// Uniforms are the values set by name on the shader, of the types Set
+	// accepts. Set checks them as they're added, values added directly panic
+	// when applied if they're of another type.
+	Uniforms map[string]interface{}
+	// Textures are bound to units assigned by slot name order, from 0, and
+	// the sampler uniforms named by the slots set to them.
+	Textures map[string]*texture.Texture
+
+	// slots is reused to sort the texture slots.
+	slots []string
+}
+
+func New(name string, program shader.Shader) *Material {
+	return &Material{
+		Name:     name,
+		Shader:   program,
+		Uniforms: make(map[string]interface{}),
+		Textures: make(map[string]*texture.Texture),
+	}
+}
+
+// Set sets a uniform value. Values can be bool, float32, int32,
+// mgl32.Vec2, Vec3, Vec4 or Mat4, float64 and int are converted to float32
+// and int32, and slices of 2 to 4 float64 or float32 to vectors.
+func (material *Material) Set(name string, value interface{}) error {
+	converted, err := convert(value)
+	if err != nil {
+		return fmt.Errorf("material: %s: %s: %v", material.Name, name, err)
+	}
+
+	material.Uniforms[name] = converted
+	return nil
+}
+
+func convert(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case bool, float32, int32, mgl32.Vec2, mgl32.Vec3, mgl32.Vec4, mgl32.Mat4:
+		return value, nil
+	case float64:
+		return float32(value), nil
+	case int:
+		return int32(value), nil
+	case []float32:
+		return vector(value)
+	case []float64:
+		components := make([]float32, len(value))
+		for i, component := range value {
+			components[i] = float32(component)
+		}
+		return vector(components)
+	}
+
+	return nil, fmt.Errorf("unsupported uniform type %T", value)
+}
+
+func vector(components []float32) (interface{}, error) {
+	switch len(components) {
+	case 2:
+		return mgl32.Vec2(components), nil
+	case 3:
+		return mgl32.Vec3(components), nil
+	case 4:
+		return mgl32.Vec4(components), nil
+	}
+
+	return nil, fmt.Errorf("vectors have 2 to 4 components, not %d", len(components))
+}
+
+// SetTexture puts texture in the slot read by the sampler uniform name.
+func (material *Material) SetTexture(name string, texture *texture.Texture) {
+	material.Textures[name] = texture
+}
+
+// state is what materials last set, shared since GL state is.
+var state = struct {
+	// frame is the window frame the state was set in.
+	frame   uint64
+	program uint32
+	// bound is the texture bound to each unit and target.
+	bound map[binding]uint32
+	// uniforms is the last value set by name, for each program.
+	uniforms map[uint32]map[string]interface{}
+}{
+	bound:    make(map[binding]uint32),
+	uniforms: make(map[uint32]map[string]interface{}),
+}
+
+type binding struct {
+	unit   int
+	target uint32
+}
+
+// Invalidate forgets the state materials set, so the next one sets all of
+// its own. To be called after other code uses programs, binds textures or
+// sets uniforms materials also set, and after deleting shaders or textures
+// since GL reuses their names.
+func Invalidate() {
+	state.program = 0
+	state.bound = make(map[binding]uint32)
+	state.uniforms = make(map[uint32]map[string]interface{})
+}
+
+// track forgets the state of previous frames, other code may have changed
+// it since.
+func track() {
+	if frame := window.Frame(); frame != state.frame {
+		Invalidate()
+		state.frame = frame
+	}
+}
+
+// Use makes the material's shader current and applies the material, before
+// drawing objects with it.
+func (material *Material) Use() {
+	track()
+
+	if state.program != material.Shader.ProgramId {
+		material.Shader.Use()
+		state.program = material.Shader.ProgramId
+	}
+
+	material.Apply(material.Shader)
+}
+
+// Apply sets the material's uniforms and textures on program, which has to be
+// in use. It lets a scene.Renderable draw with a material.
+func (material *Material) Apply(program shader.Shader) {
+	track()
+	state.program = program.ProgramId
+
+	last := state.uniforms[program.ProgramId]
+	if last == nil {
+		last = make(map[string]interface{})
+		state.uniforms[program.ProgramId] = last
+	}
+
+	for name, value := range material.Uniforms {
+		value, err := convert(value)
+		if err != nil {
+			panic(fmt.Sprintf("material: %s: %s: %v", material.Name, name, err))
+		}
+
+		if previous, ok := last[name]; ok && previous == value {
+			continue
+		}
+
+		setUniform(program, name, value)
+		last[name] = value
+	}
+
+	material.slots = material.slots[:0]
+	for name := range material.Textures {
+		material.slots = append(material.slots, name)
+	}
+	sort.Strings(material.slots)
+
+	for unit, name := range material.slots {
+		bound := material.Textures[name]
+		if bound == nil {
+			continue
+		}
+
+		key := binding{unit, bound.Target}
+		if state.bound[key] != bound.Id {
+			bound.Bind(unit)
+			state.bound[key] = bound.Id
+		}
+
+		sampler := int32(unit)
+		if previous, ok := last[name]; !ok || previous != sampler {
+			program.SetUniformInt(name, sampler)
+			last[name] = sampler
+		}
+	}
+
+	gldebug.Check("material.Apply")
+}
+
+func setUniform(program shader.Shader, name string, value interface{}) {
+	switch value := value.(type) {
+	case bool:
+		program.SetUniformBool(name, value)
+	case float32:
+		program.SetUniformFloat(name, value)
+	case int32:
+		program.SetUniformInt(name, value)
+	case mgl32.Vec2:
+		program.SetUniformVec2(name, value[0], value[1])
+	case mgl32.Vec3:
+		program.SetUniformVec3(name, value[0], value[1], value[2])
+	case mgl32.Vec4:
+		program.SetUniformVec4(name, value[0], value[1], value[2], value[3])
+	case mgl32.Mat4:
+		program.SetUniformMat4(name, value)
+	}
+}
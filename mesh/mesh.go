@@ -0,0 +1,204 @@
+// Package mesh owns the GL objects needed to draw a piece of geometry.
+package mesh
+
+import (
+	"fmt"
+
+	"github.com/go-gl/example/buffer"
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/utils"
+	"github.com/go-gl/gl/v2.1/gl"
+	core "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Index is the type of index buffer elements, 16-bit indices halve the size
+// of the buffer for meshes with less than 65536 vertices.
+type Index interface {
+	~uint16 | ~uint32
+}
+
+// SubMesh is a range of the index buffer drawn on its own, typically a part
+// of a model using a different material.
+type SubMesh struct {
+	Name       string
+	FirstIndex int
+	IndexCount int
+	// BaseVertex is added to every index of the range.
+	BaseVertex int
+}
+
+type Mesh struct {
+	// Primitive drawn, gl.TRIANGLES unless changed.
+	Mode uint32
+
+	VertexCount int
+	IndexCount  int
+	SubMeshes   []SubMesh
+
+	vao          uint32
+	vertexBuffer interface{ Delete() }
+	indexBuffer  interface{ Delete() }
+	indexType    uint32
+	indexSize    int
+}
+
+// New uploads vertices and indices to new buffers and records layout in a
+// vertex array object. vertices can be a slice of vertex structs or a flat
+// slice of components, the vertex count is derived from the layout's stride.
+func New[V any, I Index](vertices []V, indices []I, layout utils.VertexLayout) *Mesh {
+	mesh := newMesh(vertices, layout)
+
+	indexBuffer := buffer.NewWithData(gl.ELEMENT_ARRAY_BUFFER, gl.STATIC_DRAW, indices)
+	mesh.indexBuffer = indexBuffer
+	mesh.indexSize = indexBuffer.ElementSize()
+	mesh.IndexCount = len(indices)
+
+	if mesh.indexSize == 2 {
+		mesh.indexType = gl.UNSIGNED_SHORT
+	} else {
+		mesh.indexType = gl.UNSIGNED_INT
+	}
+
+	// The element buffer binding is part of the VAO state, unbind the VAO
+	// first so it's kept.
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, 0)
+
+	gldebug.Check("mesh.New")
+
+	return mesh
+}
+
+// NewArrays creates a mesh without index buffer, drawn with glDrawArrays.
+func NewArrays[V any](vertices []V, layout utils.VertexLayout) *Mesh {
+	mesh := newMesh(vertices, layout)
+
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	gldebug.Check("mesh.NewArrays")
+
+	return mesh
+}
+
+func newMesh[V any](vertices []V, layout utils.VertexLayout) *Mesh {
+	mesh := &Mesh{Mode: gl.TRIANGLES}
+
+	gl.GenVertexArrays(1, &mesh.vao)
+	gl.BindVertexArray(mesh.vao)
+
+	vertexBuffer := buffer.NewWithData(gl.ARRAY_BUFFER, gl.STATIC_DRAW, vertices)
+	mesh.vertexBuffer = vertexBuffer
+
+	if layout.Stride > 0 {
+		mesh.VertexCount = vertexBuffer.Size() / int(layout.Stride)
+	}
+
+	layout.Apply()
+
+	return mesh
+}
+
+// Label names the mesh's vertex array and buffers in GL debug output.
+func (mesh *Mesh) Label(name string) {
+	gldebug.Label(gl.VERTEX_ARRAY, mesh.vao, name)
+
+	if labeler, ok := mesh.vertexBuffer.(interface{ Label(string) }); ok {
+		labeler.Label(name + " vertices")
+	}
+
+	if labeler, ok := mesh.indexBuffer.(interface{ Label(string) }); ok {
+		labeler.Label(name + " indices")
+	}
+}
+
+// AddSubMesh registers a range of the index buffer that DrawSubMesh can draw.
+func (mesh *Mesh) AddSubMesh(subMesh SubMesh) error {
+	if mesh.indexBuffer == nil {
+		return fmt.Errorf("mesh: sub meshes need an index buffer")
+	}
+
+	if subMesh.FirstIndex < 0 || subMesh.IndexCount < 0 || subMesh.FirstIndex+subMesh.IndexCount > mesh.IndexCount {
+		return fmt.Errorf("mesh: sub mesh %q indices %d-%d out of range of %d",
+			subMesh.Name, subMesh.FirstIndex, subMesh.FirstIndex+subMesh.IndexCount, mesh.IndexCount)
+	}
+
+	mesh.SubMeshes = append(mesh.SubMeshes, subMesh)
+	return nil
+}
+
+// Draw draws the whole mesh with the bound shader.
+func (mesh *Mesh) Draw() {
+	mesh.DrawInstanced(1)
+}
+
+// DrawInstanced draws the mesh count times, shaders tell instances apart with
+// gl_InstanceID.
+func (mesh *Mesh) DrawInstanced(count int) {
+	gl.BindVertexArray(mesh.vao)
+
+	if mesh.indexBuffer == nil {
+		if count == 1 {
+			gl.DrawArrays(mesh.Mode, 0, int32(mesh.VertexCount))
+		} else {
+			// Missing from the v2.1 binding, window.Create initializes the core one
+			core.DrawArraysInstanced(mesh.Mode, 0, int32(mesh.VertexCount), int32(count))
+		}
+	} else {
+		mesh.drawElements(0, mesh.IndexCount, 0, count)
+	}
+
+	gl.BindVertexArray(0)
+	gldebug.Check("mesh.Draw")
+}
+
+// DrawSubMesh draws the index range of SubMeshes[index].
+func (mesh *Mesh) DrawSubMesh(index int) {
+	mesh.DrawSubMeshInstanced(index, 1)
+}
+
+func (mesh *Mesh) DrawSubMeshInstanced(index int, count int) {
+	subMesh := mesh.SubMeshes[index]
+
+	gl.BindVertexArray(mesh.vao)
+	mesh.drawElements(subMesh.FirstIndex, subMesh.IndexCount, subMesh.BaseVertex, count)
+	gl.BindVertexArray(0)
+
+	gldebug.Check("mesh.DrawSubMesh")
+}
+
+func (mesh *Mesh) drawElements(first int, indexCount int, baseVertex int, count int) {
+	offset := uintptr(first * mesh.indexSize)
+
+	if count == 1 && baseVertex == 0 {
+		gl.DrawElementsWithOffset(mesh.Mode, int32(indexCount), mesh.indexType, offset)
+		return
+	}
+
+	gl.DrawElementsInstancedBaseVertex(mesh.Mode, int32(indexCount), mesh.indexType, gl.PtrOffset(int(offset)), int32(count), int32(baseVertex))
+}
+
+// Size is the number of bytes in the mesh's vertex and index buffers.
+func (mesh *Mesh) Size() int {
+	size := 0
+	for _, buffer := range []interface{}{mesh.vertexBuffer, mesh.indexBuffer} {
+		if sizer, ok := buffer.(interface{ Size() int }); ok {
+			size += sizer.Size()
+		}
+	}
+
+	return size
+}
+
+// Delete frees the vertex array and buffers, the mesh can't be drawn anymore.
+func (mesh *Mesh) Delete() {
+	gl.DeleteVertexArrays(1, &mesh.vao)
+	mesh.vertexBuffer.Delete()
+
+	if mesh.indexBuffer != nil {
+		mesh.indexBuffer.Delete()
+	}
+
+	mesh.vao = 0
+}
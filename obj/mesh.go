@@ -0,0 +1,26 @@
+package obj
+
+import (
+	"strings"
+
+	"github.com/go-gl/example/geometry"
+	"github.com/go-gl/example/mesh"
+	"github.com/go-gl/example/utils"
+)
+
+// NewMesh uploads the model with one sub mesh per group, named after the
+// group's object, group and material joined by slashes. Needs a current GL
+// context.
+func (model *Model) NewMesh() *mesh.Mesh {
+	m := mesh.New(model.Vertices, model.Indices, utils.MustLayoutOf(geometry.Vertex{}))
+
+	for _, group := range model.Groups {
+		m.SubMeshes = append(m.SubMeshes, mesh.SubMesh{
+			Name:       strings.Join([]string{group.Object, group.Group, group.Material}, "/"),
+			FirstIndex: group.FirstIndex,
+			IndexCount: group.IndexCount,
+		})
+	}
+
+	return m
+}
@@ -0,0 +1,218 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Material is a material description from an MTL library. Texture maps are
+// paths as written in the file, relative to the library.
+type Material struct {
+	Name string
+
+	Ambient  mgl32.Vec3 // Ka
+	Diffuse  mgl32.Vec3 // Kd
+	Specular mgl32.Vec3 // Ks
+	Emissive mgl32.Vec3 // Ke
+
+	Shininess       float32 // Ns
+	Opacity         float32 // d, or 1 - Tr
+	RefractiveIndex float32 // Ni
+	Illumination    int     // illum
+
+	// Physically based extension
+	Roughness float32 // Pr
+	Metallic  float32 // Pm
+
+	AmbientMap   string // map_Ka
+	DiffuseMap   string // map_Kd
+	SpecularMap  string // map_Ks
+	EmissiveMap  string // map_Ke
+	ShininessMap string // map_Ns
+	OpacityMap   string // map_d
+	BumpMap      string // map_Bump, bump
+	NormalMap    string // norm
+	RoughnessMap string // map_Pr
+	MetallicMap  string // map_Pm
+}
+
+// ParseMaterials reads an MTL library, keyed by material name.
+func ParseMaterials(r io.Reader) (map[string]*Material, error) {
+	materials := map[string]*Material{}
+	var material *Material
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		keyword, args := fields[0], fields[1:]
+
+		if keyword == "newmtl" {
+			material = &Material{
+				Name:            strings.Join(args, " "),
+				Diffuse:         mgl32.Vec3{1, 1, 1},
+				Opacity:         1,
+				RefractiveIndex: 1,
+			}
+			materials[material.Name] = material
+			continue
+		}
+
+		if material == nil {
+			return nil, fmt.Errorf("line %d: %s before newmtl", lineNumber, keyword)
+		}
+
+		if err := parseMaterialLine(material, keyword, args); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+func parseMaterialLine(material *Material, keyword string, args []string) error {
+	var err error
+
+	switch strings.ToLower(keyword) {
+	case "ka":
+		material.Ambient, err = parseColor(args)
+	case "kd":
+		material.Diffuse, err = parseColor(args)
+	case "ks":
+		material.Specular, err = parseColor(args)
+	case "ke":
+		material.Emissive, err = parseColor(args)
+	case "ns":
+		material.Shininess, err = parseFloat(args)
+	case "d":
+		material.Opacity, err = parseFloat(args)
+	case "tr":
+		var transparency float32
+		transparency, err = parseFloat(args)
+		material.Opacity = 1 - transparency
+	case "ni":
+		material.RefractiveIndex, err = parseFloat(args)
+	case "pr":
+		material.Roughness, err = parseFloat(args)
+	case "pm":
+		material.Metallic, err = parseFloat(args)
+	case "illum":
+		if len(args) == 0 {
+			return fmt.Errorf("illum without a value")
+		}
+		material.Illumination, err = strconv.Atoi(args[0])
+	case "map_ka":
+		material.AmbientMap, err = parseMap(args)
+	case "map_kd":
+		material.DiffuseMap, err = parseMap(args)
+	case "map_ks":
+		material.SpecularMap, err = parseMap(args)
+	case "map_ke":
+		material.EmissiveMap, err = parseMap(args)
+	case "map_ns":
+		material.ShininessMap, err = parseMap(args)
+	case "map_d":
+		material.OpacityMap, err = parseMap(args)
+	case "map_bump", "bump":
+		material.BumpMap, err = parseMap(args)
+	case "norm":
+		material.NormalMap, err = parseMap(args)
+	case "map_pr":
+		material.RoughnessMap, err = parseMap(args)
+	case "map_pm":
+		material.MetallicMap, err = parseMap(args)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %v", keyword, err)
+	}
+
+	return nil
+}
+
+// parseColor reads "r g b", or a single value used for all three. Spectral
+// and XYZ colors aren't supported.
+func parseColor(args []string) (mgl32.Vec3, error) {
+	if len(args) > 0 && (args[0] == "spectral" || args[0] == "xyz") {
+		return mgl32.Vec3{}, fmt.Errorf("%s colors aren't supported", args[0])
+	}
+
+	values, err := parseFloats(args, 1)
+	if err != nil {
+		return mgl32.Vec3{}, err
+	}
+
+	switch len(values) {
+	case 1:
+		return mgl32.Vec3{values[0], values[0], values[0]}, nil
+	case 3:
+		return mgl32.Vec3{values[0], values[1], values[2]}, nil
+	}
+
+	return mgl32.Vec3{}, fmt.Errorf("expected 1 or 3 values, got %d", len(values))
+}
+
+func parseFloat(args []string) (float32, error) {
+	values, err := parseFloats(args, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return values[0], nil
+}
+
+// numberOfOptionArgs is how many values follow each texture map option.
+var numberOfOptionArgs = map[string]int{
+	"-blendu": 1, "-blendv": 1, "-boost": 1, "-cc": 1, "-clamp": 1,
+	"-imfchan": 1, "-mm": 2, "-o": 3, "-s": 3, "-t": 3, "-texres": 1,
+	"-bm": 1, "-type": 1,
+}
+
+// parseMap skips texture map options and returns the file name, which may
+// contain spaces.
+func parseMap(args []string) (string, error) {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		count, ok := numberOfOptionArgs[args[i]]
+		if !ok {
+			return "", fmt.Errorf("unknown option %q", args[i])
+		}
+
+		i++
+
+		// -o, -s and -t take 1 to 3 values
+		for taken := 0; taken < count && i < len(args); taken++ {
+			if _, err := strconv.ParseFloat(args[i], 32); err != nil && taken > 0 {
+				break
+			}
+			i++
+		}
+	}
+
+	if i >= len(args) {
+		return "", fmt.Errorf("missing file name")
+	}
+
+	return strings.Join(args[i:], " "), nil
+}
@@ -0,0 +1,428 @@
+// Package obj reads Wavefront OBJ models and their MTL material libraries.
+//
+// Parsing is pure Go and streams the input line by line. Faces are
+// triangulated, vertices sharing position, UV and normal are merged, and
+// missing normals are generated following the smoothing groups.
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/example/geometry"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Group is a run of triangles sharing object, group, material and smoothing
+// group, stored as a range of the model's indices.
+type Group struct {
+	Object         string
+	Group          string
+	Material       string
+	SmoothingGroup int
+	FirstIndex     int
+	IndexCount     int
+}
+
+type Model struct {
+	geometry.Mesh
+
+	Groups            []Group
+	MaterialLibraries []string
+	// Materials is only filled by Load, Parse leaves the libraries to the
+	// caller.
+	Materials map[string]*Material
+}
+
+// Load parses an OBJ file and the material libraries it references, which
+// are looked up relative to the OBJ file.
+func Load(fsys fs.FS, name string) (*Model, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	model, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	model.Materials = map[string]*Material{}
+	for _, library := range model.MaterialLibraries {
+		libraryName := path.Join(path.Dir(name), library)
+
+		materials, err := loadMaterials(fsys, libraryName)
+		if err != nil {
+			return nil, err
+		}
+
+		for materialName, material := range materials {
+			model.Materials[materialName] = material
+		}
+	}
+
+	return model, nil
+}
+
+func loadMaterials(fsys fs.FS, name string) (map[string]*Material, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	materials, err := ParseMaterials(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return materials, nil
+}
+
+// faceVertex holds the 0-based position, UV and normal indices of a face
+// corner, -1 when the corner has no UV or normal.
+type faceVertex struct {
+	position int
+	uv       int
+	normal   int
+}
+
+// vertexKey identifies a vertex for deduplication. Corners without a normal
+// get one generated per smoothing group, so the group is part of the key,
+// and face is set to keep flat shaded faces (smoothing off) from sharing.
+type vertexKey struct {
+	faceVertex
+	smoothingGroup int
+	face           int
+}
+
+type parser struct {
+	model *Model
+
+	positions []mgl32.Vec3
+	uvs       []mgl32.Vec2
+	normals   []mgl32.Vec3
+
+	vertices map[vertexKey]uint32
+	// Vertices that need a generated normal
+	generated map[uint32]bool
+
+	object, group, material string
+	smoothingGroup          int
+	faces                   int
+}
+
+// Parse reads an OBJ model. Errors report the line they happened on.
+func Parse(r io.Reader) (*Model, error) {
+	p := &parser{
+		model:     &Model{},
+		vertices:  map[vertexKey]uint32{},
+		generated: map[uint32]bool{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	lineNumber := 0
+	var continued string
+
+	for scanner.Scan() {
+		lineNumber++
+		line := continued + scanner.Text()
+		continued = ""
+
+		if strings.HasSuffix(line, "\\") {
+			continued = strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+
+		if err := p.parseLine(line); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p.closeGroup()
+	p.generateNormals()
+	p.model.ComputeTangents()
+
+	return p.model, nil
+}
+
+func (p *parser) parseLine(line string) error {
+	if comment := strings.IndexByte(line, '#'); comment >= 0 {
+		line = line[:comment]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	keyword, args := fields[0], fields[1:]
+
+	switch keyword {
+	case "v":
+		values, err := parseFloats(args, 3)
+		if err != nil {
+			return err
+		}
+		p.positions = append(p.positions, mgl32.Vec3{values[0], values[1], values[2]})
+
+	case "vt":
+		values, err := parseFloats(args, 1)
+		if err != nil {
+			return err
+		}
+		uv := mgl32.Vec2{values[0]}
+		if len(values) > 1 {
+			uv[1] = values[1]
+		}
+		p.uvs = append(p.uvs, uv)
+
+	case "vn":
+		values, err := parseFloats(args, 3)
+		if err != nil {
+			return err
+		}
+		p.normals = append(p.normals, mgl32.Vec3{values[0], values[1], values[2]})
+
+	case "f":
+		return p.parseFace(args)
+
+	case "o":
+		p.closeGroup()
+		p.object = strings.Join(args, " ")
+
+	case "g":
+		p.closeGroup()
+		p.group = strings.Join(args, " ")
+
+	case "usemtl":
+		p.closeGroup()
+		p.material = strings.Join(args, " ")
+
+	case "s":
+		p.closeGroup()
+		p.smoothingGroup = 0
+		if len(args) > 0 && args[0] != "off" {
+			group, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid smoothing group %q", args[0])
+			}
+			p.smoothingGroup = group
+		}
+
+	case "mtllib":
+		p.model.MaterialLibraries = append(p.model.MaterialLibraries, args...)
+
+	default:
+		// Lines, points, curves and free form surfaces aren't supported and
+		// skipped like unknown statements.
+	}
+
+	return nil
+}
+
+// maxFaceVertices bounds the work triangulating a single face, ear clipping a
+// concave one is cubic in its vertex count.
+const maxFaceVertices = 256
+
+func (p *parser) parseFace(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("face with %d vertices", len(args))
+	}
+
+	if len(args) > maxFaceVertices {
+		return fmt.Errorf("face with %d vertices, at most %d are supported", len(args), maxFaceVertices)
+	}
+
+	corners := make([]faceVertex, len(args))
+	for i, arg := range args {
+		corner, err := p.parseFaceVertex(arg)
+		if err != nil {
+			return err
+		}
+		corners[i] = corner
+	}
+
+	p.faces++
+
+	indices := make([]uint32, len(corners))
+	for i, corner := range corners {
+		indices[i] = p.vertex(corner)
+	}
+
+	positions := make([]mgl32.Vec3, len(corners))
+	for i, corner := range corners {
+		positions[i] = p.positions[corner.position]
+	}
+
+	for _, triangle := range triangulate(positions) {
+		p.model.Indices = append(p.model.Indices, indices[triangle[0]], indices[triangle[1]], indices[triangle[2]])
+	}
+
+	return nil
+}
+
+// parseFaceVertex parses v, v/vt, v//vn or v/vt/vn, resolving negative
+// indices relative to the end of the lists read so far.
+func (p *parser) parseFaceVertex(arg string) (faceVertex, error) {
+	parts := strings.Split(arg, "/")
+	if len(parts) > 3 {
+		return faceVertex{}, fmt.Errorf("invalid face vertex %q", arg)
+	}
+
+	corner := faceVertex{uv: -1, normal: -1}
+	var err error
+
+	if corner.position, err = resolveIndex(parts[0], len(p.positions)); err != nil {
+		return faceVertex{}, fmt.Errorf("face vertex %q: %v", arg, err)
+	}
+
+	if len(parts) > 1 && parts[1] != "" {
+		if corner.uv, err = resolveIndex(parts[1], len(p.uvs)); err != nil {
+			return faceVertex{}, fmt.Errorf("face vertex %q: texture coordinate %v", arg, err)
+		}
+	}
+
+	if len(parts) > 2 && parts[2] != "" {
+		if corner.normal, err = resolveIndex(parts[2], len(p.normals)); err != nil {
+			return faceVertex{}, fmt.Errorf("face vertex %q: normal %v", arg, err)
+		}
+	}
+
+	return corner, nil
+}
+
+func resolveIndex(value string, count int) (int, error) {
+	index, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", value)
+	}
+
+	if index < 0 {
+		index += count
+	} else {
+		index--
+	}
+
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index %s out of range of %d", value, count)
+	}
+
+	return index, nil
+}
+
+func (p *parser) vertex(corner faceVertex) uint32 {
+	key := vertexKey{faceVertex: corner}
+	if corner.normal < 0 {
+		key.smoothingGroup = p.smoothingGroup
+		if p.smoothingGroup == 0 {
+			key.face = p.faces
+		}
+	}
+
+	if index, ok := p.vertices[key]; ok {
+		return index
+	}
+
+	vertex := geometry.Vertex{Position: p.positions[corner.position]}
+	if corner.uv >= 0 {
+		vertex.UV = p.uvs[corner.uv]
+	}
+	if corner.normal >= 0 {
+		vertex.Normal = p.normals[corner.normal]
+	}
+
+	index := uint32(len(p.model.Vertices))
+	p.model.Vertices = append(p.model.Vertices, vertex)
+	p.vertices[key] = index
+
+	if corner.normal < 0 {
+		p.generated[index] = true
+	}
+
+	return index
+}
+
+// closeGroup ends the current run of triangles, called whenever a statement
+// changes the object, group, material or smoothing group.
+func (p *parser) closeGroup() {
+	first := 0
+	if len(p.model.Groups) > 0 {
+		last := p.model.Groups[len(p.model.Groups)-1]
+		first = last.FirstIndex + last.IndexCount
+	}
+
+	count := len(p.model.Indices) - first
+	if count == 0 {
+		return
+	}
+
+	p.model.Groups = append(p.model.Groups, Group{
+		Object:         p.object,
+		Group:          p.group,
+		Material:       p.material,
+		SmoothingGroup: p.smoothingGroup,
+		FirstIndex:     first,
+		IndexCount:     count,
+	})
+}
+
+// generateNormals gives vertices without a normal the area weighted average
+// of the faces using them. Vertices are only shared within a smoothing group,
+// so faces outside of one end up flat.
+func (p *parser) generateNormals() {
+	if len(p.generated) == 0 {
+		return
+	}
+
+	vertices := p.model.Vertices
+	indices := p.model.Indices
+
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := indices[i], indices[i+1], indices[i+2]
+
+		// Not normalized, so larger faces weigh more
+		normal := vertices[b].Position.Sub(vertices[a].Position).Cross(vertices[c].Position.Sub(vertices[a].Position))
+
+		for _, index := range []uint32{a, b, c} {
+			if p.generated[index] {
+				vertices[index].Normal = vertices[index].Normal.Add(normal)
+			}
+		}
+	}
+
+	for index := range p.generated {
+		if vertices[index].Normal.Len() > 0 {
+			vertices[index].Normal = vertices[index].Normal.Normalize()
+		}
+	}
+}
+
+func parseFloats(args []string, minimum int) ([]float32, error) {
+	if len(args) < minimum {
+		return nil, fmt.Errorf("expected at least %d values, got %d", minimum, len(args))
+	}
+
+	values := make([]float32, len(args))
+	for i, arg := range args {
+		value, err := strconv.ParseFloat(arg, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", arg)
+		}
+		values[i] = float32(value)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,230 @@
+package obj
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestTriangulate(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon []mgl32.Vec2
+	}{
+		{"triangle", []mgl32.Vec2{{0, 0}, {1, 0}, {0, 1}}},
+		{"convex quad", []mgl32.Vec2{{0, 0}, {2, 0}, {2, 1}, {0, 1}}},
+		// Reflex corner at the second vertex, a fan from the first one covers
+		// the outside of the dart.
+		{"concave quad", []mgl32.Vec2{{0, 0}, {1, 0.3}, {2, 0}, {1, 2}}},
+		{"concave quad reflex first", []mgl32.Vec2{{1, 0.3}, {2, 0}, {1, 2}, {0, 0}}},
+		{"L shape", []mgl32.Vec2{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}},
+		{"comb", []mgl32.Vec2{{0, 0}, {5, 0}, {5, 2}, {4, 2}, {4, 1}, {3, 1}, {3, 2}, {2, 2}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}},
+	}
+
+	for _, test := range tests {
+		for _, clockwise := range []bool{false, true} {
+			polygon := append([]mgl32.Vec2(nil), test.polygon...)
+			if clockwise {
+				for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+					polygon[i], polygon[j] = polygon[j], polygon[i]
+				}
+			}
+
+			t.Run(fmt.Sprintf("%s clockwise=%v", test.name, clockwise), func(t *testing.T) {
+				// In the XZ plane, Z pointing down the 2D Y axis
+				positions := make([]mgl32.Vec3, len(polygon))
+				for i, point := range polygon {
+					positions[i] = mgl32.Vec3{point.X(), 0, -point.Y()}
+				}
+
+				triangles := triangulate(positions)
+				if len(triangles) != len(polygon)-2 {
+					t.Fatalf("%d triangles, want %d", len(triangles), len(polygon)-2)
+				}
+
+				area := shoelace(polygon)
+
+				var sum float32
+				for _, triangle := range triangles {
+					a, b, c := polygon[triangle[0]], polygon[triangle[1]], polygon[triangle[2]]
+					triangleArea := cross2(a, b, c) / 2
+
+					// Same winding as the polygon
+					if triangleArea*area <= 0 {
+						t.Fatalf("triangle %v has area %v, polygon %v", triangle, triangleArea, area)
+					}
+					sum += triangleArea
+				}
+
+				if abs(sum-area) > 1e-5 {
+					t.Errorf("triangles cover %v, polygon %v", sum, area)
+				}
+			})
+		}
+	}
+}
+
+func shoelace(polygon []mgl32.Vec2) float32 {
+	var area float32
+	for i, current := range polygon {
+		next := polygon[(i+1)%len(polygon)]
+		area += current.X()*next.Y() - next.X()*current.Y()
+	}
+
+	return area / 2
+}
+
+func TestParseFaceLimit(t *testing.T) {
+	var source strings.Builder
+	for i := 0; i <= maxFaceVertices; i++ {
+		fmt.Fprintf(&source, "v %d 0 %d\n", i%2, i)
+	}
+
+	source.WriteString("f")
+	for i := 1; i <= maxFaceVertices+1; i++ {
+		fmt.Fprintf(&source, " %d", i)
+	}
+
+	if _, err := Parse(strings.NewReader(source.String())); err == nil {
+		t.Errorf("Parse accepted a face with %d vertices", maxFaceVertices+1)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n")
+	f.Add("v 0 0 0\nv 1 0.3 0\nv 2 0 0\nv 1 2 0\nvt 0 0\nvn 0 0 1\nf 1/1/1 2/1/1 3/1/1 4/1/1\n")
+	f.Add("o cube\ng side\nusemtl red\ns 1\nv -1 -1 0\nv 1 -1 0\nv 1 1 0\nv -1 1 0\nf -4//  -3 -2 -1\ns off\nf 1 2 3\n")
+	f.Add("mtllib a.mtl\nv 0 0 0 \\\n1\nv 1 0 0\nv 0 1 0\nv 0 0 1\nf 1/ 2/ 3/ 4/\n# comment\n")
+
+	f.Fuzz(func(t *testing.T, source string) {
+		model, err := Parse(strings.NewReader(source))
+		if err != nil {
+			return
+		}
+
+		if len(model.Indices)%3 != 0 {
+			t.Fatalf("%d indices aren't whole triangles", len(model.Indices))
+		}
+
+		for _, index := range model.Indices {
+			if int(index) >= len(model.Vertices) {
+				t.Fatalf("index %d out of range of %d vertices", index, len(model.Vertices))
+			}
+		}
+
+		next := 0
+		for _, group := range model.Groups {
+			if group.FirstIndex != next || group.IndexCount <= 0 {
+				t.Fatalf("group %+v doesn't follow index %d", group, next)
+			}
+			next += group.IndexCount
+		}
+
+		if next != len(model.Indices) {
+			t.Fatalf("groups cover %d of %d indices", next, len(model.Indices))
+		}
+	})
+}
+
+func TestParseMaterials(t *testing.T) {
+	source := `# Two materials
+newmtl brick wall
+Ka 0.1 0.1 0.1
+Kd 0.8 0.4 0.2
+Ks 0.5
+Ke 0 0 0
+Ns 32
+Tr 0.25
+Ni 1.5
+illum 2
+Pr 0.7
+Pm 0.1
+map_Kd -s 2 2 1 -clamp on textures/brick wall.png
+bump -bm 0.5 brick_bump.png
+norm brick_normal.png
+
+newmtl glass
+d 0.3
+map_d -o 0.5 glass_alpha.png # trailing comment
+`
+
+	materials, err := ParseMaterials(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(materials) != 2 {
+		t.Fatalf("%d materials, want 2", len(materials))
+	}
+
+	want := Material{
+		Name:            "brick wall",
+		Ambient:         mgl32.Vec3{0.1, 0.1, 0.1},
+		Diffuse:         mgl32.Vec3{0.8, 0.4, 0.2},
+		Specular:        mgl32.Vec3{0.5, 0.5, 0.5},
+		Shininess:       32,
+		Opacity:         0.75,
+		RefractiveIndex: 1.5,
+		Illumination:    2,
+		Roughness:       0.7,
+		Metallic:        0.1,
+		DiffuseMap:      "textures/brick wall.png",
+		BumpMap:         "brick_bump.png",
+		NormalMap:       "brick_normal.png",
+	}
+	if got := *materials["brick wall"]; got != want {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	// Defaults for what the file leaves out
+	glass := materials["glass"]
+	if glass.Diffuse != (mgl32.Vec3{1, 1, 1}) || glass.Opacity != 0.3 || glass.RefractiveIndex != 1 || glass.OpacityMap != "glass_alpha.png" {
+		t.Errorf("glass %+v", glass)
+	}
+}
+
+func TestParseMaterialsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"before newmtl", "Kd 1 1 1\n"},
+		{"two color values", "newmtl a\nKd 1 0\n"},
+		{"four color values", "newmtl a\nKd 1 0 0 1\n"},
+		{"no color values", "newmtl a\nKa\n"},
+		{"spectral", "newmtl a\nKd spectral curve.rfl\n"},
+		{"invalid number", "newmtl a\nNs shiny\n"},
+		{"illum without a value", "newmtl a\nillum\n"},
+		{"unknown map option", "newmtl a\nmap_Kd -unknown 1 a.png\n"},
+		{"map without a file", "newmtl a\nmap_Kd -s 1 1 1\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseMaterials(strings.NewReader(test.source)); err == nil {
+				t.Errorf("ParseMaterials succeeded")
+			}
+		})
+	}
+}
+
+func FuzzParseMaterials(f *testing.F) {
+	f.Add("newmtl a\nKa 0.1 0.2 0.3\nKd 1\nNs 10\nd 0.5\nillum 2\nmap_Kd a.png\n")
+	f.Add("newmtl b c\nTr 0.2\nmap_Bump -bm 2 -o 1 1 b.png\nnorm -clamp on n.png\n# comment\n")
+	f.Add("newmtl d\nKs xyz 1 1 1\nPr 0.5\nPm 1\nmap_Pr -mm 0 1 r.png\n")
+
+	f.Fuzz(func(t *testing.T, source string) {
+		materials, err := ParseMaterials(strings.NewReader(source))
+		if err != nil {
+			return
+		}
+
+		for name, material := range materials {
+			if material == nil || material.Name != name {
+				t.Fatalf("material stored as %q is %+v", name, material)
+			}
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package obj
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// triangulate splits a polygon into triangles given as indices into polygon,
+// keeping the polygon's winding. Convex polygons are fanned, concave ones are
+// ear clipped in the plane they mostly lie in. Polygons ear clipping can't
+// handle, self intersecting or degenerate ones, fall back to a fan.
+func triangulate(polygon []mgl32.Vec3) [][3]int {
+	if len(polygon) == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+
+	points, orientation := project(polygon)
+	if !convex(points, orientation) {
+		if triangles, ok := earClip(points, orientation); ok {
+			return triangles
+		}
+	}
+
+	triangles := make([][3]int, 0, len(polygon)-2)
+	for i := 1; i+1 < len(polygon); i++ {
+		triangles = append(triangles, [3]int{0, i, i + 1})
+	}
+
+	return triangles
+}
+
+// project flattens polygon onto the axis plane it mostly lies in. orientation
+// is 1 when the projected polygon is counter clockwise and -1 otherwise.
+func project(polygon []mgl32.Vec3) ([]mgl32.Vec2, float32) {
+	// Newell's method gives a normal robust to slightly non planar polygons
+	var normal mgl32.Vec3
+	for i, current := range polygon {
+		next := polygon[(i+1)%len(polygon)]
+		normal[0] += (current.Y() - next.Y()) * (current.Z() + next.Z())
+		normal[1] += (current.Z() - next.Z()) * (current.X() + next.X())
+		normal[2] += (current.X() - next.X()) * (current.Y() + next.Y())
+	}
+
+	// Drop the axis the normal is largest along and work in 2D
+	x, y := 0, 1
+	ax, ay, az := abs(normal.X()), abs(normal.Y()), abs(normal.Z())
+	switch {
+	case ax >= ay && ax >= az:
+		x, y = 1, 2
+	case ay >= az:
+		x, y = 2, 0
+	}
+
+	points := make([]mgl32.Vec2, len(polygon))
+	for i, position := range polygon {
+		points[i] = mgl32.Vec2{position[x], position[y]}
+	}
+
+	// Counter clockwise polygons have a positive area along the dropped axis
+	orientation := float32(1)
+	if normal[3-x-y] < 0 {
+		orientation = -1
+	}
+
+	return points, orientation
+}
+
+// convex reports whether every corner of the polygon turns the same way as its
+// winding. Fanning is only right for those.
+func convex(points []mgl32.Vec2, orientation float32) bool {
+	for i := range points {
+		previous := points[(i+len(points)-1)%len(points)]
+		next := points[(i+1)%len(points)]
+
+		if cross2(previous, points[i], next)*orientation <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// earClip is O(n³) in the number of corners, parseFace caps it with
+// maxFaceVertices.
+func earClip(points []mgl32.Vec2, orientation float32) ([][3]int, bool) {
+	remaining := make([]int, len(points))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	var triangles [][3]int
+
+	for len(remaining) > 3 {
+		clipped := false
+
+		for i := range remaining {
+			previous := remaining[(i+len(remaining)-1)%len(remaining)]
+			current := remaining[i]
+			next := remaining[(i+1)%len(remaining)]
+
+			if !isEar(points, remaining, previous, current, next, orientation) {
+				continue
+			}
+
+			triangles = append(triangles, [3]int{previous, current, next})
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+
+		if !clipped {
+			return nil, false
+		}
+	}
+
+	triangles = append(triangles, [3]int{remaining[0], remaining[1], remaining[2]})
+	return triangles, true
+}
+
+func isEar(points []mgl32.Vec2, remaining []int, previous, current, next int, orientation float32) bool {
+	a, b, c := points[previous], points[current], points[next]
+
+	// Reflex and degenerate corners aren't ears
+	if cross2(a, b, c)*orientation <= 0 {
+		return false
+	}
+
+	for _, index := range remaining {
+		if index == previous || index == current || index == next {
+			continue
+		}
+
+		if insideTriangle(points[index], a, b, c, orientation) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cross2(a, b, c mgl32.Vec2) float32 {
+	return (b.X()-a.X())*(c.Y()-a.Y()) - (b.Y()-a.Y())*(c.X()-a.X())
+}
+
+func insideTriangle(point, a, b, c mgl32.Vec2, orientation float32) bool {
+	return cross2(a, b, point)*orientation >= 0 &&
+		cross2(b, c, point)*orientation >= 0 &&
+		cross2(c, a, point)*orientation >= 0
+}
+
+func abs(value float32) float32 {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
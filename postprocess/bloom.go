@@ -0,0 +1,172 @@
+package postprocess
+
+import (
+	"fmt"
+
+	"github.com/go-gl/example/framebuffer"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// brightShader keeps what's brighter than threshold, fading in over knee to
+// avoid a hard edge.
+const brightShader = `
+#version 330
+
+uniform sampler2D source;
+uniform float threshold;
+uniform float knee;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+void main() {
+    vec3 color = texture(source, uv).rgb;
+    float brightness = max(color.r, max(color.g, color.b));
+    float contribution = smoothstep(threshold - knee, threshold + knee, brightness);
+    outputColor = vec4(color * contribution, 1);
+}
+`
+
+// blurShader is one direction of a separable 9 tap gaussian blur, sampling
+// between texels to get two taps from each fetch.
+const blurShader = `
+#version 330
+
+uniform sampler2D source;
+uniform vec2 texelSize;
+uniform vec2 direction;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+const float offsets[3] = float[](0, 1.3846153846, 3.2307692308);
+const float weights[3] = float[](0.2270270270, 0.3162162162, 0.0702702703);
+
+void main() {
+    vec2 offset = direction * texelSize;
+    vec3 color = texture(source, uv).rgb * weights[0];
+    for (int i = 1; i < 3; i++) {
+        color += texture(source, uv + offset * offsets[i]).rgb * weights[i];
+        color += texture(source, uv - offset * offsets[i]).rgb * weights[i];
+    }
+    outputColor = vec4(color, 1);
+}
+`
+
+const combineShader = `
+#version 330
+
+uniform sampler2D source;
+uniform sampler2D bloom;
+uniform float intensity;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(source, uv);
+    outputColor = vec4(color.rgb + texture(bloom, uv).rgb * intensity, color.a);
+}
+`
+
+// bloomPasses is how many times the bright parts are blurred both ways.
+const bloomPasses = 4
+
+// Bloom creates the "bloom" pass making bright parts glow. It works on HDR
+// colors so it goes before tonemapping. The bright parts are picked and
+// blurred at half the stack's resolution. Parameters are threshold, the
+// brightness above which colors glow, 1 by default, knee, the range over
+// which the glow fades in, and intensity, how much glow is added.
+func Bloom() (*Pass, error) {
+	pass, err := NewPass("bloom", combineShader)
+	if err != nil {
+		return nil, err
+	}
+
+	pass.Parameters["threshold"] = 1
+	pass.Parameters["knee"] = 0.5
+	pass.Parameters["intensity"] = 0.6
+
+	var (
+		bright, blur shader.Shader
+		buffers      [2]*framebuffer.Framebuffer
+	)
+
+	pass.detach = func() {
+		for i, buffer := range buffers {
+			if buffer != nil {
+				buffer.Delete()
+				buffers[i] = nil
+			}
+		}
+	}
+
+	pass.free = func() {
+		for _, program := range []shader.Shader{bright, blur} {
+			if program.ProgramId != 0 {
+				program.Delete()
+			}
+		}
+		pass.detach()
+	}
+
+	bright, err = shader.CreateFromSource(vertexShader, brightShader)
+	if err == nil {
+		blur, err = shader.CreateFromSource(vertexShader, blurShader)
+	}
+	if err != nil {
+		pass.Delete()
+		return nil, fmt.Errorf("postprocess: bloom: %v", err)
+	}
+	bright.Label("bloom bright")
+	blur.Label("bloom blur")
+
+	// Half the stack's scale, which isn't known before the pass is added
+	pass.attach = func(stack *Stack) error {
+		for i := range buffers {
+			if buffers[i] != nil {
+				continue
+			}
+
+			buffer, err := framebuffer.New(framebuffer.Options{
+				WindowScale: stack.scale / 2,
+				Color:       []int32{gl.RGBA16F_ARB},
+			})
+			if err != nil {
+				return err
+			}
+			buffer.Label(fmt.Sprintf("postprocess bloom %d", i))
+			buffers[i] = buffer
+		}
+
+		return nil
+	}
+
+	pass.prepare = func(stack *Stack, input *texture.Texture) {
+		buffers[0].Bind()
+		bright.Use()
+		bright.SetUniformFloat("threshold", pass.Parameters["threshold"])
+		bright.SetUniformFloat("knee", pass.Parameters["knee"])
+		stack.draw(bright, input)
+
+		for i := 0; i < bloomPasses; i++ {
+			buffers[1].Bind()
+			blur.Use()
+			blur.SetUniformVec2("direction", 1, 0)
+			stack.draw(blur, buffers[0].Color[0])
+
+			buffers[0].Bind()
+			blur.SetUniformVec2("direction", 0, 1)
+			stack.draw(blur, buffers[1].Color[0])
+		}
+
+		pass.Textures["bloom"] = buffers[0].Color[0]
+	}
+
+	return pass, nil
+}
@@ -0,0 +1,275 @@
+package postprocess
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Operator maps HDR colors to the displayable range.
+type Operator int
+
+const (
+	// Reinhard is x / (1 + x), soft but desaturating bright colors.
+	Reinhard Operator = iota
+	// ACES is Krzysztof Narkowicz's fit of the ACES filmic curve, with more
+	// contrast.
+	ACES
+)
+
+const tonemapShader = `
+#version 330
+
+uniform sampler2D source;
+uniform float exposure;
+uniform float aces;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+vec3 tonemap(vec3 x) {
+    if (aces > 0.5) {
+        return clamp((x * (2.51 * x + 0.03)) / (x * (2.43 * x + 0.59) + 0.14), 0, 1);
+    }
+    return x / (1 + x);
+}
+
+void main() {
+    vec4 color = texture(source, uv);
+    outputColor = vec4(tonemap(color.rgb * exposure), color.a);
+}
+`
+
+// Tonemap creates the "tonemap" pass, with the parameter exposure scaling
+// colors first, 1 by default, and aces, 1 for ACES and 0 for Reinhard, set
+// from operator. It belongs after passes working on HDR colors like bloom and
+// before gamma.
+func Tonemap(operator Operator) (*Pass, error) {
+	pass, err := NewPass("tonemap", tonemapShader)
+	if err != nil {
+		return nil, err
+	}
+
+	pass.Parameters["exposure"] = 1
+	pass.Parameters["aces"] = 0
+	if operator == ACES {
+		pass.Parameters["aces"] = 1
+	}
+	return pass, nil
+}
+
+const gammaShader = `
+#version 330
+
+uniform sampler2D source;
+uniform float gamma;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(source, uv);
+    outputColor = vec4(pow(max(color.rgb, 0), vec3(1 / gamma)), color.a);
+}
+`
+
+// Gamma creates the "gamma" pass encoding linear colors for the screen, with
+// the parameter gamma, 2.2 by default.
+func Gamma() (*Pass, error) {
+	pass, err := NewPass("gamma", gammaShader)
+	if err != nil {
+		return nil, err
+	}
+
+	pass.Parameters["gamma"] = 2.2
+	return pass, nil
+}
+
+const vignetteShader = `
+#version 330
+
+uniform sampler2D source;
+uniform float strength;
+uniform float radius;
+uniform float softness;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(source, uv);
+    float fromCenter = length(uv - 0.5) * 1.41421356;
+    float shade = smoothstep(radius, radius - softness, fromCenter);
+    outputColor = vec4(color.rgb * mix(1, shade, strength), color.a);
+}
+`
+
+// Vignette creates the "vignette" pass darkening the corners. Its parameters
+// are strength, 0 to 1, radius, where darkening starts from the center with
+// the corners at 1, and softness, how far it takes to reach full strength.
+func Vignette() (*Pass, error) {
+	pass, err := NewPass("vignette", vignetteShader)
+	if err != nil {
+		return nil, err
+	}
+
+	pass.Parameters["strength"] = 0.5
+	pass.Parameters["radius"] = 1
+	pass.Parameters["softness"] = 0.6
+	return pass, nil
+}
+
+const colorGradingShader = `
+#version 330
+
+uniform sampler2D source;
+uniform sampler3D lut;
+uniform float lutSize;
+uniform float strength;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(source, uv);
+    // Sample texel centers, 0 and 1 are the first and last texels
+    vec3 coordinates = clamp(color.rgb, 0, 1) * (lutSize - 1) / lutSize + 0.5 / lutSize;
+    vec3 graded = texture(lut, coordinates).rgb;
+    outputColor = vec4(mix(color.rgb, graded, strength), color.a);
+}
+`
+
+// ColorGrading creates the "color grading" pass replacing colors with the
+// ones they index in lut, a 3D texture such as LoadLUT returns. The parameter
+// strength blends from the original colors, at 0, to the graded ones, at 1.
+// The pass expects colors from 0 to 1, after tonemapping and gamma.
+func ColorGrading(lut *texture.Texture) (*Pass, error) {
+	pass, err := NewPass("color grading", colorGradingShader)
+	if err != nil {
+		return nil, err
+	}
+
+	pass.Textures["lut"] = lut
+	pass.Parameters["lutSize"] = float32(lut.Width)
+	pass.Parameters["strength"] = 1
+	return pass, nil
+}
+
+// LoadLUT loads a color grading lookup table from an image of N blocks of N
+// by N texels laid side by side, such as 256x16 or 1024x32. Red goes right
+// within each block, green down and blue from block to block. Rendering a
+// neutral table, graded in an image editor along with a screenshot, gives
+// the table for the look.
+func LoadLUT(path string) (*texture.Texture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("postprocess: %s: %v", path, err)
+	}
+
+	bounds := img.Bounds()
+	size := bounds.Dy()
+	if size == 0 || bounds.Dx() != size*size {
+		return nil, fmt.Errorf("postprocess: %s: a %dx%d image isn't N blocks of NxN", path, bounds.Dx(), bounds.Dy())
+	}
+
+	sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("postprocess: %s: unsupported image type %T", path, img)
+	}
+
+	slices := make([]image.Image, size)
+	for blue := range slices {
+		x := bounds.Min.X + blue*size
+		slices[blue] = sub.SubImage(image.Rect(x, bounds.Min.Y, x+size, bounds.Max.Y))
+	}
+
+	lut, err := texture.VolumeFromImages(slices, texture.Options{InternalFormat: gl.RGBA8})
+	if err != nil {
+		return nil, fmt.Errorf("postprocess: %s: %v", path, err)
+	}
+
+	lut.Label(path)
+	return lut, nil
+}
+
+const fxaaShader = `
+#version 330
+
+uniform sampler2D source;
+uniform vec2 texelSize;
+uniform float spanMax;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+const vec3 lumaWeights = vec3(0.299, 0.587, 0.114);
+const float reduceMin = 1.0 / 128;
+const float reduceMultiplier = 1.0 / 8;
+
+void main() {
+    vec3 rgbNW = texture(source, uv + vec2(-1, -1) * texelSize).rgb;
+    vec3 rgbNE = texture(source, uv + vec2(1, -1) * texelSize).rgb;
+    vec3 rgbSW = texture(source, uv + vec2(-1, 1) * texelSize).rgb;
+    vec3 rgbSE = texture(source, uv + vec2(1, 1) * texelSize).rgb;
+    vec4 center = texture(source, uv);
+
+    float lumaNW = dot(rgbNW, lumaWeights);
+    float lumaNE = dot(rgbNE, lumaWeights);
+    float lumaSW = dot(rgbSW, lumaWeights);
+    float lumaSE = dot(rgbSE, lumaWeights);
+    float lumaM = dot(center.rgb, lumaWeights);
+    float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+    float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+    // Blur along the edge, perpendicular to the luma gradient
+    vec2 direction = vec2(
+        -((lumaNW + lumaNE) - (lumaSW + lumaSE)),
+        (lumaNW + lumaSW) - (lumaNE + lumaSE));
+    float reduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * reduceMultiplier, reduceMin);
+    float scale = 1 / (min(abs(direction.x), abs(direction.y)) + reduce);
+    direction = clamp(direction * scale, -spanMax, spanMax) * texelSize;
+
+    vec3 rgbA = 0.5 * (
+        texture(source, uv + direction * (1.0 / 3 - 0.5)).rgb +
+        texture(source, uv + direction * (2.0 / 3 - 0.5)).rgb);
+    vec3 rgbB = rgbA * 0.5 + 0.25 * (
+        texture(source, uv - direction * 0.5).rgb +
+        texture(source, uv + direction * 0.5).rgb);
+
+    float lumaB = dot(rgbB, lumaWeights);
+    if (lumaB < lumaMin || lumaB > lumaMax) {
+        outputColor = vec4(rgbA, center.a);
+    } else {
+        outputColor = vec4(rgbB, center.a);
+    }
+}
+`
+
+// FXAA creates the "fxaa" pass smoothing aliased edges. It works on display
+// colors so it goes last, after gamma. The parameter spanMax is the longest
+// blur in texels, 8 by default.
+func FXAA() (*Pass, error) {
+	pass, err := NewPass("fxaa", fxaaShader)
+	if err != nil {
+		return nil, err
+	}
+
+	pass.Parameters["spanMax"] = 8
+	return pass, nil
+}
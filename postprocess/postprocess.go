@@ -0,0 +1,327 @@
+// Package postprocess applies full screen effects to a rendered scene.
+//
+// The scene is drawn into an HDR framebuffer between Begin and End, then End
+// runs the enabled passes in order, each reading the previous one's output,
+// the last one drawing to the window:
+//
+//	stack, err := postprocess.New(postprocess.Options{Samples: 4})
+//	bloom, err := postprocess.Bloom()
+//	tonemap, err := postprocess.Tonemap(postprocess.ACES)
+//	gamma, err := postprocess.Gamma()
+//	err = stack.Add(bloom, tonemap, gamma)
+//
+//	stack.Begin()
+//	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+//	// draw the scene
+//	stack.End()
+package postprocess
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-gl/example/framebuffer"
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/material"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// vertexShader covers the screen with one triangle generated from the vertex
+// IDs, no vertex buffer is needed.
+const vertexShader = `
+#version 330
+
+out vec2 uv;
+
+void main() {
+    uv = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    gl_Position = vec4(uv * 2 - 1, 0, 1);
+}
+`
+
+// copyShader draws the scene as is when no pass is enabled.
+const copyShader = `
+#version 330
+
+uniform sampler2D source;
+
+in vec2 uv;
+
+out vec4 outputColor;
+
+void main() {
+    outputColor = texture(source, uv);
+}
+`
+
+// Pass is a full screen effect. Its fragment shader gets the previous pass'
+// output as the sampler2D uniform source, the size of one of its texels as
+// the vec2 uniform texelSize, and the texture coordinates as the vec2 input
+// uv.
+type Pass struct {
+	Name    string
+	Enabled bool
+	Shader  shader.Shader
+
+	// Parameters are set as float uniforms every time the pass runs, and
+	// can be changed at any time.
+	Parameters map[string]float32
+	// Textures are bound to the units after source's and set to the
+	// sampler uniforms named by their keys.
+	Textures map[string]*texture.Texture
+
+	// attach runs when the pass is added to a stack, to create what
+	// prepare needs at the stack's resolution.
+	attach func(stack *Stack) error
+	// detach releases what attach created, when adding fails.
+	detach func()
+	// prepare runs before the pass draws, for passes drawing to their own
+	// framebuffers first.
+	prepare func(stack *Stack, input *texture.Texture)
+	// free releases what attach and prepare use.
+	free func()
+}
+
+// NewPass creates an enabled pass drawing with fragmentSource.
+func NewPass(name string, fragmentSource string) (*Pass, error) {
+	program, err := shader.CreateFromSource(vertexShader, fragmentSource)
+	if err != nil {
+		return nil, fmt.Errorf("postprocess: %s: %v", name, err)
+	}
+
+	program.Label(name)
+
+	return &Pass{
+		Name:       name,
+		Enabled:    true,
+		Shader:     program,
+		Parameters: make(map[string]float32),
+		Textures:   make(map[string]*texture.Texture),
+	}, nil
+}
+
+// Delete frees the pass' shader and any framebuffer it has.
+func (pass *Pass) Delete() {
+	pass.Shader.Delete()
+
+	if pass.free != nil {
+		pass.free()
+	}
+}
+
+type Options struct {
+	// Samples above 1 multisample the scene.
+	Samples int
+	// Scale is the resolution of the scene and passes relative to the
+	// window, 1 by default.
+	Scale float32
+}
+
+type Stack struct {
+	Passes []*Pass
+
+	// Scene is drawn to between Begin and End, RGBA16F with a depth and
+	// stencil buffer.
+	Scene *framebuffer.Framebuffer
+
+	scale    float32
+	pingPong [2]*framebuffer.Framebuffer
+	copy     shader.Shader
+	// vao is empty, core profiles need one bound to draw.
+	vao uint32
+}
+
+// New creates a stack without passes, which copies the scene to the window.
+func New(options Options) (*Stack, error) {
+	if options.Scale == 0 {
+		options.Scale = 1
+	}
+
+	stack := &Stack{scale: options.Scale}
+
+	var err error
+	stack.Scene, err = framebuffer.New(framebuffer.Options{
+		WindowScale: options.Scale,
+		Color:       []int32{gl.RGBA16F_ARB},
+		Depth:       gl.DEPTH24_STENCIL8,
+		Samples:     options.Samples,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("postprocess: scene: %v", err)
+	}
+	stack.Scene.Label("postprocess scene")
+
+	for i := range stack.pingPong {
+		stack.pingPong[i], err = framebuffer.New(framebuffer.Options{
+			WindowScale: options.Scale,
+			Color:       []int32{gl.RGBA16F_ARB},
+		})
+		if err != nil {
+			stack.Delete()
+			return nil, fmt.Errorf("postprocess: %v", err)
+		}
+		stack.pingPong[i].Label(fmt.Sprintf("postprocess ping pong %d", i))
+	}
+
+	stack.copy, err = shader.CreateFromSource(vertexShader, copyShader)
+	if err != nil {
+		stack.Delete()
+		return nil, fmt.Errorf("postprocess: copy: %v", err)
+	}
+	stack.copy.Label("postprocess copy")
+
+	gl.GenVertexArrays(1, &stack.vao)
+	gldebug.Check("postprocess.New")
+
+	return stack, nil
+}
+
+// Add appends passes, they run in the order added. Passes creating their own
+// framebuffers create them here, if that fails none of the passes is added
+// and the framebuffers already created are freed.
+func (stack *Stack) Add(passes ...*Pass) error {
+	for i, pass := range passes {
+		if pass.attach == nil {
+			continue
+		}
+
+		if err := pass.attach(stack); err != nil {
+			// Passes already in the stack keep what they use
+			for _, attached := range passes[:i+1] {
+				if attached.detach != nil && !slices.Contains(stack.Passes, attached) {
+					attached.detach()
+				}
+			}
+
+			return fmt.Errorf("postprocess: %s: %v", pass.Name, err)
+		}
+	}
+
+	stack.Passes = append(stack.Passes, passes...)
+	return nil
+}
+
+// Pass returns the first pass called name, nil if there's none.
+func (stack *Stack) Pass(name string) *Pass {
+	for _, pass := range stack.Passes {
+		if pass.Name == name {
+			return pass
+		}
+	}
+
+	return nil
+}
+
+// Begin makes drawing go to the scene framebuffer.
+func (stack *Stack) Begin() {
+	stack.Scene.Bind()
+}
+
+// End runs the enabled passes and leaves the default framebuffer bound. Depth
+// testing and blending are off while the passes run and restored afterwards.
+func (stack *Stack) End() {
+	stack.Scene.Resolve()
+
+	depthTest, blend := gl.IsEnabled(gl.DEPTH_TEST), gl.IsEnabled(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.BLEND)
+	gl.BindVertexArray(stack.vao)
+
+	var enabled []*Pass
+	for _, pass := range stack.Passes {
+		if pass.Enabled {
+			enabled = append(enabled, pass)
+		}
+	}
+
+	input := stack.Scene.Color[0]
+	if len(enabled) == 0 {
+		framebuffer.BindDefault()
+		stack.draw(stack.copy, input)
+	}
+
+	for i, pass := range enabled {
+		if pass.prepare != nil {
+			pass.prepare(stack, input)
+		}
+
+		output := stack.pingPong[i%2]
+		if i == len(enabled)-1 {
+			framebuffer.BindDefault()
+		} else {
+			output.Bind()
+		}
+
+		stack.apply(pass, input)
+		input = output.Color[0]
+	}
+
+	gl.BindVertexArray(0)
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+	if blend {
+		gl.Enable(gl.BLEND)
+	}
+
+	// The passes used their own shaders and bound their inputs
+	material.Invalidate()
+	gldebug.Check("postprocess.End")
+}
+
+// apply draws pass with its parameters and textures to the bound framebuffer.
+func (stack *Stack) apply(pass *Pass, input *texture.Texture) {
+	pass.Shader.Use()
+
+	for name, value := range pass.Parameters {
+		pass.Shader.SetUniformFloat(name, value)
+	}
+
+	unit := 1
+	for name, bound := range pass.Textures {
+		bound.Bind(unit)
+		pass.Shader.SetUniformInt(name, int32(unit))
+		unit++
+	}
+
+	stack.draw(pass.Shader, input)
+}
+
+// draw runs program over the bound framebuffer with input as source.
+func (stack *Stack) draw(program shader.Shader, input *texture.Texture) {
+	program.Use()
+	program.SetUniformInt("source", 0)
+	program.SetUniformVec2("texelSize", 1/float32(input.Width), 1/float32(input.Height))
+	input.Bind(0)
+
+	gl.BindVertexArray(stack.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
+
+// Delete frees the stack's framebuffers and its passes.
+func (stack *Stack) Delete() {
+	for _, pass := range stack.Passes {
+		pass.Delete()
+	}
+	stack.Passes = nil
+
+	if stack.Scene != nil {
+		stack.Scene.Delete()
+	}
+
+	for _, buffer := range stack.pingPong {
+		if buffer != nil {
+			buffer.Delete()
+		}
+	}
+
+	if stack.copy.ProgramId != 0 {
+		stack.copy.Delete()
+	}
+
+	if stack.vao != 0 {
+		gl.DeleteVertexArrays(1, &stack.vao)
+	}
+}
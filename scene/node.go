@@ -0,0 +1,203 @@
+package scene
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Node is a transform in a hierarchy, placed relative to its parent. Its
+// matrices are recomputed when read after a change, not when changed.
+type Node struct {
+	Name string
+	// Renderable is drawn with the node's world matrix, nil for nodes only
+	// grouping others.
+	Renderable *Renderable
+	// Hidden skips drawing the node and its descendants.
+	Hidden bool
+
+	position mgl32.Vec3
+	rotation mgl32.Quat
+	scale    mgl32.Vec3
+
+	parent   *Node
+	children []*Node
+
+	local mgl32.Mat4
+	world mgl32.Mat4
+	// localDirty is set when the transform changed since local was computed,
+	// worldDirty when it or any ancestor's did. A node with a dirty world
+	// has only descendants with dirty worlds.
+	localDirty bool
+	worldDirty bool
+}
+
+// NewNode creates a node at its parent's origin, unrotated and unscaled.
+func NewNode(name string) *Node {
+	return &Node{
+		Name:       name,
+		rotation:   mgl32.QuatIdent(),
+		scale:      mgl32.Vec3{1, 1, 1},
+		local:      mgl32.Ident4(),
+		world:      mgl32.Ident4(),
+		localDirty: true,
+		worldDirty: true,
+	}
+}
+
+func (node *Node) Position() mgl32.Vec3 {
+	return node.position
+}
+
+func (node *Node) SetPosition(position mgl32.Vec3) {
+	node.position = position
+	node.invalidate()
+}
+
+// Translate moves the node by offset in its parent's space.
+func (node *Node) Translate(offset mgl32.Vec3) {
+	node.SetPosition(node.position.Add(offset))
+}
+
+func (node *Node) Rotation() mgl32.Quat {
+	return node.rotation
+}
+
+func (node *Node) SetRotation(rotation mgl32.Quat) {
+	node.rotation = rotation.Normalize()
+	node.invalidate()
+}
+
+// Rotate turns the node by rotation in its own space, after its current
+// rotation.
+func (node *Node) Rotate(rotation mgl32.Quat) {
+	node.SetRotation(node.rotation.Mul(rotation))
+}
+
+func (node *Node) Scale() mgl32.Vec3 {
+	return node.scale
+}
+
+func (node *Node) SetScale(scale mgl32.Vec3) {
+	node.scale = scale
+	node.invalidate()
+}
+
+// LocalMatrix is the transform from the node's space to its parent's:
+// scale, then rotation, then translation.
+func (node *Node) LocalMatrix() mgl32.Mat4 {
+	if node.localDirty {
+		node.local = mgl32.Translate3D(node.position.X(), node.position.Y(), node.position.Z()).
+			Mul4(node.rotation.Mat4()).
+			Mul4(mgl32.Scale3D(node.scale.X(), node.scale.Y(), node.scale.Z()))
+		node.localDirty = false
+	}
+
+	return node.local
+}
+
+// WorldMatrix is the transform from the node's space to the root's.
+func (node *Node) WorldMatrix() mgl32.Mat4 {
+	if node.worldDirty {
+		if node.parent == nil {
+			node.world = node.LocalMatrix()
+		} else {
+			node.world = node.parent.WorldMatrix().Mul4(node.LocalMatrix())
+		}
+		node.worldDirty = false
+	}
+
+	return node.world
+}
+
+// WorldPosition is the node's origin in the root's space.
+func (node *Node) WorldPosition() mgl32.Vec3 {
+	return node.WorldMatrix().Col(3).Vec3()
+}
+
+func (node *Node) invalidate() {
+	node.localDirty = true
+	node.invalidateWorld()
+}
+
+// invalidateWorld marks the world matrices of node and its descendants for
+// recomputing. Subtrees already dirty are skipped.
+func (node *Node) invalidateWorld() {
+	if node.worldDirty {
+		return
+	}
+
+	node.worldDirty = true
+	for _, child := range node.children {
+		child.invalidateWorld()
+	}
+}
+
+func (node *Node) Parent() *Node {
+	return node.parent
+}
+
+// Children returns the node's children in attachment order. The slice must
+// not be modified.
+func (node *Node) Children() []*Node {
+	return node.children
+}
+
+// Attach makes child a child of node, detaching it from its previous parent.
+// The child keeps its local transform, so it moves with its new parent.
+// Attaching a node under itself or one of its descendants panics.
+func (node *Node) Attach(child *Node) {
+	for ancestor := node; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor == child {
+			panic("scene: attaching " + child.Name + " under itself")
+		}
+	}
+
+	child.Detach()
+	child.parent = node
+	node.children = append(node.children, child)
+	child.invalidateWorld()
+}
+
+// Detach removes the node from its parent, it becomes the root of its own
+// tree. Detaching a root does nothing.
+func (node *Node) Detach() {
+	parent := node.parent
+	if parent == nil {
+		return
+	}
+
+	for i, sibling := range parent.children {
+		if sibling == node {
+			parent.children = append(parent.children[:i:i], parent.children[i+1:]...)
+			break
+		}
+	}
+
+	node.parent = nil
+	node.invalidateWorld()
+}
+
+// Walk calls visit for the node and its descendants depth first, parents
+// before children. Returning false from visit skips the node's descendants.
+func (node *Node) Walk(visit func(node *Node) bool) {
+	if !visit(node) {
+		return
+	}
+
+	for _, child := range node.children {
+		child.Walk(visit)
+	}
+}
+
+// Find returns the first node called name among node and its descendants,
+// depth first, or nil.
+func (node *Node) Find(name string) *Node {
+	var found *Node
+	node.Walk(func(visited *Node) bool {
+		if found == nil && visited.Name == name {
+			found = visited
+		}
+		return found == nil
+	})
+
+	return found
+}
@@ -0,0 +1,154 @@
+// Package scene places meshes in a hierarchy of transforms and draws them.
+//
+// Nodes are positioned relative to their parents, moving a node moves its
+// descendants. Nodes with a Renderable are drawn by Scene.Draw, which sets
+// the mat4 uniforms projection, camera and model of the renderable's shader,
+// as gl41core-cube's shader names them:
+//
+//	world := scene.New()
+//	table := scene.NewNode("table")
+//	table.Renderable = &scene.Renderable{Mesh: tableMesh, Shader: program}
+//	world.Root.Attach(table)
+//
+//	// every frame
+//	world.Draw(view)
+package scene
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/go-gl/example/camera"
+	"github.com/go-gl/example/mesh"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Material sets the uniforms and textures a renderable's shader needs besides
+// the matrices. Apply is called with the shader in use. Draws are grouped by
+// material with ==, pointers by identity. Materials that can't be compared,
+// like structs holding slices or maps, aren't grouped.
+type Material interface {
+	Apply(program shader.Shader)
+}
+
+// Renderable is what a node draws.
+type Renderable struct {
+	Mesh   *mesh.Mesh
+	Shader shader.Shader
+	// Material is applied before drawing, if not nil.
+	Material Material
+	// SubMeshes are the indices of the Mesh.SubMeshes drawn, the whole mesh
+	// is drawn when empty. Nodes sharing a mesh with a material per sub mesh
+	// each draw their own.
+	SubMeshes []int
+}
+
+type Scene struct {
+	Root *Node
+
+	// drawList is reused between draws.
+	drawList []drawItem
+}
+
+type drawItem struct {
+	renderable *Renderable
+	model      mgl32.Mat4
+	// group is where the item's material first appears, for groupMaterials.
+	group int
+}
+
+// New creates a scene with an empty root node called "root".
+func New() *Scene {
+	return &Scene{Root: NewNode("root")}
+}
+
+// Find returns the first node called name, see Node.Find.
+func (scene *Scene) Find(name string) *Node {
+	return scene.Root.Find(name)
+}
+
+// Draw draws every visible renderable seen from view. Renderables sharing a
+// shader are drawn together so the camera uniforms are set once per shader,
+// and sharing a material one after the other.
+func (scene *Scene) Draw(view camera.Camera) {
+	scene.drawList = scene.drawList[:0]
+	scene.Root.Walk(func(node *Node) bool {
+		if node.Hidden {
+			return false
+		}
+
+		if node.Renderable != nil && node.Renderable.Mesh != nil {
+			scene.drawList = append(scene.drawList, drawItem{renderable: node.Renderable, model: node.WorldMatrix()})
+		}
+		return true
+	})
+
+	sort.SliceStable(scene.drawList, func(i, j int) bool {
+		return scene.drawList[i].renderable.Shader.ProgramId < scene.drawList[j].renderable.Shader.ProgramId
+	})
+	groupMaterials(scene.drawList)
+
+	viewMatrix, projection := view.View(), view.Projection()
+
+	var program shader.Shader
+	for i, item := range scene.drawList {
+		if i == 0 || item.renderable.Shader.ProgramId != program.ProgramId {
+			program = item.renderable.Shader
+			program.Use()
+			program.SetUniformMat4("projection", projection)
+			program.SetUniformMat4("camera", viewMatrix)
+		}
+
+		if item.renderable.Material != nil {
+			item.renderable.Material.Apply(program)
+		}
+
+		program.SetUniformMat4("model", item.model)
+		if len(item.renderable.SubMeshes) == 0 {
+			item.renderable.Mesh.Draw()
+		}
+		for _, subMesh := range item.renderable.SubMeshes {
+			item.renderable.Mesh.DrawSubMesh(subMesh)
+		}
+	}
+}
+
+// groupMaterials moves items with the same material next to each other within
+// each run of the same shader, keeping the order materials first appear in.
+func groupMaterials(items []drawItem) {
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && items[end].renderable.Shader.ProgramId == items[start].renderable.Shader.ProgramId {
+			end++
+		}
+
+		first := make(map[interface{}]int)
+		for i := start; i < end; i++ {
+			key := groupKey(items[i].renderable.Material, i)
+			if _, ok := first[key]; !ok {
+				first[key] = i
+			}
+			items[i].group = first[key]
+		}
+
+		run := items[start:end]
+		sort.SliceStable(run, func(i, j int) bool {
+			return run[i].group < run[j].group
+		})
+
+		start = end
+	}
+}
+
+// groupKey is what material is grouped by. Materials that can't be map keys
+// get a key of their own.
+func groupKey(material Material, index int) interface{} {
+	if material != nil && !reflect.ValueOf(material).Comparable() {
+		return ungrouped(index)
+	}
+
+	return material
+}
+
+type ungrouped int
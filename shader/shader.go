@@ -1,12 +1,13 @@
 package shader
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/go-gl/example/gldebug"
 	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 type Shader struct {
@@ -24,13 +25,29 @@ func checkShaderCompileStatus(shader uint32) error {
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
 
-		fmt.Printf("Failed to compile shader: %v", log)
-    return errors.New("Failed to compile")
+		return fmt.Errorf("failed to compile: %v", log)
 	}
 
   return nil
 }
 
+func checkProgramLinkStatus(program uint32) error {
+	var success int32 = -1
+	gl.GetProgramiv(program, gl.LINK_STATUS, &success)
+
+	if success == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+
+		return fmt.Errorf("failed to link program: %v", log)
+	}
+
+	return nil
+}
+
 func Create(vertexPath string, fragmentPath string) Shader { 
   // Load vertex file
   vertexFileContent, err := os.ReadFile(vertexPath)
@@ -50,7 +67,21 @@ func Create(vertexPath string, fragmentPath string) Shader {
 
   fragmentSource := string(fragmentFileContent)
 
+  shader, err := CreateFromSource(vertexSource, fragmentSource)
+
+  if err != nil {
+    fmt.Println(err)
+  }
+
+  shader.Label(vertexPath + " + " + fragmentPath)
 
+  return shader
+}
+
+// CreateFromSource builds a program from GLSL sources held in memory, for
+// shaders embedded in Go code. Give it a Label, debug output can't name it
+// otherwise.
+func CreateFromSource(vertexSource string, fragmentSource string) (Shader, error) {
 	// Vertext shader setup
 	// ================================
 	glVertexSourceInt, freeVertexFn := gl.Strs(vertexSource + "\x00")
@@ -61,10 +92,8 @@ func Create(vertexPath string, fragmentPath string) Shader {
 	gl.CompileShader(vertexShader)
   defer gl.DeleteShader(vertexShader)
 
-  err = checkShaderCompileStatus(vertexShader)
-
-  if err != nil {
-    fmt.Println("Failed to compile Vertex Shader.")
+  if err := checkShaderCompileStatus(vertexShader); err != nil {
+    return Shader{}, fmt.Errorf("vertex shader: %v", err)
   }
 
 	// Fragment shader setup
@@ -78,10 +107,8 @@ func Create(vertexPath string, fragmentPath string) Shader {
 	gl.CompileShader(fragmentShader)
   defer gl.DeleteShader(fragmentShader)
 
-  err = checkShaderCompileStatus(fragmentShader)
-
-  if err != nil {
-    fmt.Println("Failed to compile Fragment Shader")
+  if err := checkShaderCompileStatus(fragmentShader); err != nil {
+    return Shader{}, fmt.Errorf("fragment shader: %v", err)
   }
 
 	// Setup shader program
@@ -91,19 +118,30 @@ func Create(vertexPath string, fragmentPath string) Shader {
 	gl.AttachShader(programId, fragmentShader)
 	gl.LinkProgram(programId)
 
-  err = checkShaderCompileStatus(programId)
-
-  if err != nil {
-    fmt.Println("Failed to compile Shader Program")
+  if err := checkProgramLinkStatus(programId); err != nil {
+    gl.DeleteProgram(programId)
+    return Shader{}, err
   }
 
+  gldebug.Check("shader.CreateFromSource")
+
   return Shader{
     ProgramId: programId,
-  } 
+  }, nil
+}
+
+// Label names the program in GL debug output.
+func (shader Shader) Label(name string) {
+  gldebug.Label(gl.PROGRAM, shader.ProgramId, name)
 }
 
 func (shader Shader) Use() {
   gl.UseProgram(shader.ProgramId)
+  gldebug.Check("shader.Use")
+}
+
+func (shader Shader) Delete() {
+  gl.DeleteProgram(shader.ProgramId)
 }
 
 func (shader Shader) SetUniformBool(name string, value bool) {
@@ -122,8 +160,23 @@ func (shader Shader) SetUniformFloat(name string, value float32) {
   gl.Uniform1f(gl.GetUniformLocation(shader.ProgramId, nameCStr), value)
 }
 
+func (shader Shader) SetUniformVec2(name string, v0 float32, v1 float32) {
+  nameCStr := gl.Str(name + "\x00")
+  gl.Uniform2f(gl.GetUniformLocation(shader.ProgramId, nameCStr), v0, v1)
+}
+
+func (shader Shader) SetUniformVec3(name string, v0 float32, v1 float32, v2 float32) {
+  nameCStr := gl.Str(name + "\x00")
+  gl.Uniform3f(gl.GetUniformLocation(shader.ProgramId, nameCStr), v0, v1, v2)
+}
+
 func (shader Shader) SetUniformVec4(name string, v0 float32, v1 float32, v2 float32, v3 float32) {
   nameCStr := gl.Str(name + "\x00")
   gl.Uniform4f(gl.GetUniformLocation(shader.ProgramId, nameCStr), v0, v1, v2, v3)
 }
 
+func (shader Shader) SetUniformMat4(name string, value mgl32.Mat4) {
+  nameCStr := gl.Str(name + "\x00")
+  gl.UniformMatrix4fv(gl.GetUniformLocation(shader.ProgramId, nameCStr), 1, false, &value[0])
+}
+
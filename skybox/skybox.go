@@ -0,0 +1,125 @@
+// Package skybox draws a cube map environment behind the scene.
+package skybox
+
+import (
+	"fmt"
+
+	"github.com/go-gl/example/geometry"
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/material"
+	"github.com/go-gl/example/mesh"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/texture"
+	"github.com/go-gl/example/utils"
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The view's translation is dropped so the box stays centered on the camera,
+// and writing w as z puts every fragment on the far plane.
+const vertexShader = `
+#version 330
+
+uniform mat4 projection;
+uniform mat4 view;
+
+layout(location = 0) in vec3 position;
+
+out vec3 direction;
+
+void main() {
+    direction = position;
+    vec4 clip = projection * mat4(mat3(view)) * vec4(position, 1);
+    gl_Position = clip.xyww;
+}
+`
+
+const fragmentShader = `
+#version 330
+
+uniform samplerCube environment;
+
+in vec3 direction;
+
+out vec4 outputColor;
+
+void main() {
+    outputColor = texture(environment, direction);
+}
+`
+
+type Skybox struct {
+	// Cubemap is drawn, it's owned by the caller.
+	Cubemap *texture.Texture
+
+	shader shader.Shader
+	box    *mesh.Mesh
+}
+
+// New creates a skybox drawing cubemap. It enables
+// gl.TEXTURE_CUBE_MAP_SEAMLESS, which all cube maps benefit from.
+func New(cubemap *texture.Texture) (*Skybox, error) {
+	if cubemap.Target != gl.TEXTURE_CUBE_MAP {
+		return nil, fmt.Errorf("skybox: texture isn't a cube map")
+	}
+
+	program, err := shader.CreateFromSource(vertexShader, fragmentShader)
+	if err != nil {
+		return nil, fmt.Errorf("skybox: %v", err)
+	}
+	program.Label("skybox")
+
+	program.Use()
+	program.SetUniformInt("environment", 0)
+
+	shape := geometry.Box(2, 2, 2, 1, 1, 1)
+	box := mesh.New(shape.Vertices, shape.Indices, utils.MustLayoutOf(geometry.Vertex{}))
+	box.Label("skybox")
+
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
+	material.Invalidate()
+	gldebug.Check("skybox.New")
+
+	return &Skybox{Cubemap: cubemap, shader: program, box: box}, nil
+}
+
+// Draw draws the skybox where nothing has been drawn yet, with the camera's
+// view and projection matrices. Drawing it after the opaque geometry saves
+// shading the hidden parts. Depth test, depth writes and face culling are
+// restored to what they were.
+func (skybox *Skybox) Draw(view mgl32.Mat4, projection mgl32.Mat4) {
+	var depthFunc int32
+	var depthMask bool
+	gl.GetIntegerv(gl.DEPTH_FUNC, &depthFunc)
+	gl.GetBooleanv(gl.DEPTH_WRITEMASK, &depthMask)
+	cullFace := gl.IsEnabled(gl.CULL_FACE)
+
+	// Fragments are at depth 1, which the cleared depth buffer holds
+	gl.DepthFunc(gl.LEQUAL)
+	gl.DepthMask(false)
+	// The box is seen from inside
+	gl.Disable(gl.CULL_FACE)
+
+	skybox.shader.Use()
+	skybox.shader.SetUniformMat4("view", view)
+	skybox.shader.SetUniformMat4("projection", projection)
+	skybox.Cubemap.Bind(0)
+
+	skybox.box.Draw()
+
+	gl.DepthFunc(uint32(depthFunc))
+	gl.DepthMask(depthMask)
+	if cullFace {
+		gl.Enable(gl.CULL_FACE)
+	}
+
+	// The shader and cube map replaced the materials' own
+	material.Invalidate()
+	gldebug.Check("skybox.Draw")
+}
+
+// Delete frees the skybox's shader and mesh but not its cube map.
+func (skybox *Skybox) Delete() {
+	skybox.shader.Delete()
+	skybox.box.Delete()
+}
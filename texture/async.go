@@ -0,0 +1,140 @@
+package texture
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/go-gl/example/window"
+)
+
+// Loader loads textures without stalling the main thread: files are read,
+// decoded and converted on worker goroutines, then uploaded on the main
+// thread with window.DoAsyncOr, within its per-frame budget.
+type Loader struct {
+	// Placeholder is drawn in place of textures still loading or that failed
+	// to load, a mid grey pixel unless replaced.
+	Placeholder *Texture
+
+	workers chan struct{}
+}
+
+// NewLoader creates a loader decoding up to workers images at a time, one per
+// CPU if workers is 0. It creates the placeholder, so it has to be called on
+// the main thread.
+func NewLoader(workers int) *Loader {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	grey := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	grey.SetNRGBA(0, 0, color.NRGBA{128, 128, 128, 255})
+
+	placeholder := FromImage(grey, Options{})
+	placeholder.Label("placeholder")
+
+	return &Loader{Placeholder: placeholder, workers: make(chan struct{}, workers)}
+}
+
+// Handle is a texture being loaded.
+type Handle struct {
+	Path string
+
+	loader  *Loader
+	mutex   sync.Mutex
+	texture *Texture
+	err     error
+	done    chan struct{}
+}
+
+// Load starts loading a file as Load does and returns immediately. It can be
+// called from any goroutine.
+func (loader *Loader) Load(path string, options Options) *Handle {
+	handle := &Handle{Path: path, loader: loader, done: make(chan struct{})}
+
+	go func() {
+		loader.workers <- struct{}{}
+		prepared, err := prepareFile(path, options)
+		<-loader.workers
+
+		if err != nil {
+			handle.finish(nil, err)
+			return
+		}
+
+		window.DoAsyncOr(func() {
+			handle.finish(prepared.Upload())
+		}, func() {
+			handle.finish(nil, fmt.Errorf("texture: %s: window closed before the upload", path))
+		})
+	}()
+
+	return handle
+}
+
+func prepareFile(path string, options Options) (*Prepared, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Prepare(path, data, options)
+}
+
+func (handle *Handle) finish(texture *Texture, err error) {
+	handle.mutex.Lock()
+	handle.texture, handle.err = texture, err
+	handle.mutex.Unlock()
+
+	close(handle.done)
+}
+
+// Texture returns the loaded texture, or the loader's placeholder until it's
+// ready or if loading failed.
+func (handle *Handle) Texture() *Texture {
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	if handle.texture == nil {
+		return handle.loader.Placeholder
+	}
+
+	return handle.texture
+}
+
+// Bind binds Texture to texture unit unit.
+func (handle *Handle) Bind(unit int) {
+	handle.Texture().Bind(unit)
+}
+
+// Ready reports whether loading is over, successfully or not.
+func (handle *Handle) Ready() bool {
+	select {
+	case <-handle.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done is closed when loading is over. The upload happens on the main thread,
+// wait there with window.Wait rather than receiving from it.
+func (handle *Handle) Done() <-chan struct{} {
+	return handle.done
+}
+
+// Err is the reason loading failed, nil while loading or after a success.
+func (handle *Handle) Err() error {
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	return handle.err
+}
+
+// Delete frees the placeholder, textures loaded are owned by the caller.
+func (loader *Loader) Delete() {
+	loader.Placeholder.Delete()
+}
@@ -0,0 +1,93 @@
+package texture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-gl/example/texture/atlas"
+)
+
+// Atlas is a set of images packed into a few textures by the atlas package
+// or cmd/atlaspack.
+type Atlas struct {
+	Pages []*Texture
+
+	regions map[string]SubTexture
+}
+
+// SubTexture is one image of an atlas.
+type SubTexture struct {
+	Name    string
+	Texture *Texture
+	// Size in pixels.
+	Width  int
+	Height int
+	// UV holds U0, V0, U1, V1, the bottom left and top right corners.
+	UV [4]float32
+}
+
+// LoadAtlas loads an atlas' JSON file and its pages. FlipY is always set, the
+// UVs are meant for it.
+func LoadAtlas(path string, options Options) (*Atlas, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	metadata, err := atlas.ReadMetadata(file)
+	if err != nil {
+		return nil, fmt.Errorf("texture: %s: %v", path, err)
+	}
+
+	options.FlipY = true
+	result := &Atlas{regions: make(map[string]SubTexture, len(metadata.Regions))}
+
+	for _, page := range metadata.Pages {
+		texture, err := Load(filepath.Join(filepath.Dir(path), page.File), options)
+		if err != nil {
+			result.Delete()
+			return nil, err
+		}
+
+		result.Pages = append(result.Pages, texture)
+	}
+
+	for name, region := range metadata.Regions {
+		result.regions[name] = SubTexture{
+			Name:    name,
+			Texture: result.Pages[region.Page],
+			Width:   region.Width,
+			Height:  region.Height,
+			UV:      region.UV,
+		}
+	}
+
+	return result, nil
+}
+
+// SubTexture returns the image called name.
+func (atlas *Atlas) SubTexture(name string) (SubTexture, bool) {
+	subTexture, ok := atlas.regions[name]
+	return subTexture, ok
+}
+
+// Names lists the atlas' images in sorted order.
+func (atlas *Atlas) Names() []string {
+	names := make([]string, 0, len(atlas.regions))
+	for name := range atlas.regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (atlas *Atlas) Delete() {
+	for _, page := range atlas.Pages {
+		page.Delete()
+	}
+	atlas.Pages = nil
+}
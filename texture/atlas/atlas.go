@@ -0,0 +1,323 @@
+// Package atlas packs many small images into a few large ones, so they can be
+// drawn without switching textures. It's pure Go, see texture.LoadAtlas for
+// loading the result and cmd/atlaspack for a command line front end.
+//
+// An atlas is saved as PNG pages and a JSON file describing where each image
+// ended up:
+//
+//	{
+//	  "pages": [{"file": "sprites.png", "width": 512, "height": 256}],
+//	  "regions": {
+//	    "ui/button": {"page": 0, "x": 1, "y": 1, "width": 64, "height": 32, "uv": [0.002, 0.871, 0.127, 0.996]}
+//	  }
+//	}
+//
+// x and y are in pixels from the top left of the page. uv holds U0, V0, U1,
+// V1 with V going up from the bottom, as for pages loaded with
+// texture.Options.FlipY.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/example/texture/tga"
+)
+
+type Options struct {
+	// Maximum page size, 2048 by default. Images that don't fit on one page
+	// go to the next.
+	MaxWidth  int
+	MaxHeight int
+	// Padding is the number of transparent pixels between images.
+	Padding int
+	// Extrude repeats the edge pixels of each image outwards, so filtering
+	// and mipmaps don't blend in neighbouring images.
+	Extrude int
+}
+
+// Sprite is an image to pack, named by its path for images loaded with
+// LoadSprites.
+type Sprite struct {
+	Name  string
+	Image image.Image
+}
+
+type Atlas struct {
+	Pages   []*image.NRGBA
+	Regions map[string]Region
+}
+
+// Region is where a sprite is in the atlas, without padding and extrusion.
+type Region struct {
+	Page   int        `json:"page"`
+	X      int        `json:"x"`
+	Y      int        `json:"y"`
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	UV     [4]float32 `json:"uv"`
+}
+
+// Metadata is the contents of an atlas' JSON file.
+type Metadata struct {
+	Pages   []Page            `json:"pages"`
+	Regions map[string]Region `json:"regions"`
+}
+
+type Page struct {
+	// File is relative to the JSON file.
+	File   string `json:"file"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Pack places sprites on as few pages as it can. Larger sprites are placed
+// first and the result only depends on the sprites, not their order. Pages
+// are cropped to the area used.
+func Pack(sprites []Sprite, options Options) (*Atlas, error) {
+	if options.MaxWidth == 0 {
+		options.MaxWidth = 2048
+	}
+	if options.MaxHeight == 0 {
+		options.MaxHeight = 2048
+	}
+	if options.Padding < 0 || options.Extrude < 0 {
+		return nil, fmt.Errorf("atlas: negative padding or extrusion")
+	}
+
+	sorted := append([]Sprite(nil), sprites...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Image.Bounds().Size(), sorted[j].Image.Bounds().Size()
+		if a.Y != b.Y {
+			return a.Y > b.Y
+		}
+		if a.X != b.X {
+			return a.X > b.X
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	// Padding goes right of and below each image, the bins are larger by it
+	// so the last one on a page doesn't need any
+	border := options.Extrude*2 + options.Padding
+	var packers []*Packer
+	placed := make([]image.Rectangle, len(sorted))
+	pageOf := make([]int, len(sorted))
+	regions := make(map[string]Region, len(sorted))
+
+	for i, sprite := range sorted {
+		if _, ok := regions[sprite.Name]; ok {
+			return nil, fmt.Errorf("atlas: two images named %q", sprite.Name)
+		}
+		regions[sprite.Name] = Region{}
+
+		size := sprite.Image.Bounds().Size()
+		width, height := size.X+border, size.Y+border
+
+		found := false
+		for page, packer := range packers {
+			if placed[i], found = packer.Insert(width, height); found {
+				pageOf[i] = page
+				break
+			}
+		}
+
+		if !found {
+			packer := NewPacker(options.MaxWidth+options.Padding, options.MaxHeight+options.Padding)
+			if placed[i], found = packer.Insert(width, height); !found {
+				return nil, fmt.Errorf("atlas: %s is %dx%d, larger than a %dx%d page", sprite.Name, size.X, size.Y, options.MaxWidth, options.MaxHeight)
+			}
+
+			packers = append(packers, packer)
+			pageOf[i] = len(packers) - 1
+		}
+	}
+
+	// Crop the pages to the images on them
+	sizes := make([]image.Point, len(packers))
+	for i, rect := range placed {
+		page := pageOf[i]
+		sizes[page].X = max(sizes[page].X, rect.Max.X-options.Padding)
+		sizes[page].Y = max(sizes[page].Y, rect.Max.Y-options.Padding)
+	}
+
+	atlas := &Atlas{Regions: regions}
+	for _, size := range sizes {
+		atlas.Pages = append(atlas.Pages, image.NewNRGBA(image.Rectangle{Max: size}))
+	}
+
+	for i, sprite := range sorted {
+		page := atlas.Pages[pageOf[i]]
+		bounds := sprite.Image.Bounds()
+		inner := image.Rectangle{Min: placed[i].Min, Max: placed[i].Min.Add(bounds.Size())}.Add(image.Pt(options.Extrude, options.Extrude))
+
+		draw.Draw(page, inner, sprite.Image, bounds.Min, draw.Src)
+		extrude(page, inner, options.Extrude)
+
+		pageSize := page.Rect.Size()
+		atlas.Regions[sprite.Name] = Region{
+			Page:   pageOf[i],
+			X:      inner.Min.X,
+			Y:      inner.Min.Y,
+			Width:  inner.Dx(),
+			Height: inner.Dy(),
+			UV: [4]float32{
+				float32(inner.Min.X) / float32(pageSize.X),
+				1 - float32(inner.Max.Y)/float32(pageSize.Y),
+				float32(inner.Max.X) / float32(pageSize.X),
+				1 - float32(inner.Min.Y)/float32(pageSize.Y),
+			},
+		}
+	}
+
+	return atlas, nil
+}
+
+// extrude copies the edges of rect outwards by count pixels, corners
+// included.
+func extrude(page *image.NRGBA, rect image.Rectangle, count int) {
+	if count == 0 || rect.Empty() {
+		return
+	}
+
+	pixel := func(x, y int) []uint8 {
+		return page.Pix[page.PixOffset(x, y) : page.PixOffset(x, y)+4]
+	}
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for k := 1; k <= count; k++ {
+			copy(pixel(rect.Min.X-k, y), pixel(rect.Min.X, y))
+			copy(pixel(rect.Max.X-1+k, y), pixel(rect.Max.X-1, y))
+		}
+	}
+
+	row := func(y int) []uint8 {
+		return page.Pix[page.PixOffset(rect.Min.X-count, y):page.PixOffset(rect.Max.X+count, y)]
+	}
+	for k := 1; k <= count; k++ {
+		copy(row(rect.Min.Y-k), row(rect.Min.Y))
+		copy(row(rect.Max.Y-1+k), row(rect.Max.Y-1))
+	}
+}
+
+// Metadata describes the atlas with pages saved as name.png, or name_0.png,
+// name_1.png and so on when there are several.
+func (atlas *Atlas) Metadata(name string) Metadata {
+	metadata := Metadata{Regions: atlas.Regions}
+
+	for i, page := range atlas.Pages {
+		file := name + ".png"
+		if len(atlas.Pages) > 1 {
+			file = fmt.Sprintf("%s_%d.png", name, i)
+		}
+
+		metadata.Pages = append(metadata.Pages, Page{File: file, Width: page.Rect.Dx(), Height: page.Rect.Dy()})
+	}
+
+	return metadata
+}
+
+// Save writes the pages and name.json to dir.
+func (atlas *Atlas) Save(dir string, name string) error {
+	metadata := atlas.Metadata(name)
+
+	for i, page := range metadata.Pages {
+		if err := writePNG(filepath.Join(dir, page.File), atlas.Pages[i]); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, name+".json"), append(data, '\n'), 0o644)
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadMetadata decodes an atlas' JSON file and checks its regions are on
+// its pages.
+func ReadMetadata(r io.Reader) (*Metadata, error) {
+	var metadata Metadata
+	if err := json.NewDecoder(r).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("atlas: %v", err)
+	}
+
+	for name, region := range metadata.Regions {
+		if region.Page < 0 || region.Page >= len(metadata.Pages) {
+			return nil, fmt.Errorf("atlas: %s is on page %d of %d", name, region.Page, len(metadata.Pages))
+		}
+	}
+
+	return &metadata, nil
+}
+
+// LoadSprites decodes the images under dir, naming each by its path relative
+// to dir without extension, like "ui/button". Files that aren't PNG, JPEG,
+// TGA or another format registered with the image package are skipped.
+func LoadSprites(fsys fs.FS, dir string) ([]Sprite, error) {
+	var sprites []Sprite
+
+	err := fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		img, err := decode(fsys, name)
+		if err == image.ErrFormat {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("atlas: %s: %v", name, err)
+		}
+
+		relative := strings.TrimPrefix(strings.TrimPrefix(name, dir), "/")
+		if dir == "." {
+			relative = name
+		}
+		sprites = append(sprites, Sprite{Name: strings.TrimSuffix(relative, path.Ext(relative)), Image: img})
+
+		return nil
+	})
+
+	return sprites, err
+}
+
+func decode(fsys fs.FS, name string) (image.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(path.Ext(name), ".tga") {
+		return tga.Decode(file)
+	}
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
@@ -0,0 +1,247 @@
+package atlas
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPackerInsert(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	packer := NewPacker(256, 256)
+	var placed []image.Rectangle
+
+	for i := 0; i < 500; i++ {
+		rect, ok := packer.Insert(1+random.Intn(40), 1+random.Intn(40))
+		if !ok {
+			continue
+		}
+
+		if !rect.In(image.Rect(0, 0, packer.Width, packer.Height)) {
+			t.Fatalf("%v is outside the %dx%d bin", rect, packer.Width, packer.Height)
+		}
+
+		for _, other := range placed {
+			if rect.Overlaps(other) {
+				t.Fatalf("%v overlaps %v", rect, other)
+			}
+		}
+
+		placed = append(placed, rect)
+	}
+
+	if len(placed) < 50 {
+		t.Errorf("only %d rectangles fit", len(placed))
+	}
+}
+
+func TestPackerFull(t *testing.T) {
+	packer := NewPacker(4, 4)
+
+	for i := 0; i < 4; i++ {
+		if _, ok := packer.Insert(2, 2); !ok {
+			t.Fatalf("quarter %d doesn't fit", i)
+		}
+	}
+
+	if rect, ok := packer.Insert(1, 1); ok {
+		t.Errorf("full bin placed %v", rect)
+	}
+}
+
+// sprites returns opaque images of assorted sizes whose pixels encode the
+// sprite and position, so misplaced pixels are told apart.
+func sprites(count int) []Sprite {
+	random := rand.New(rand.NewSource(2))
+	var result []Sprite
+
+	for i := 0; i < count; i++ {
+		img := image.NewNRGBA(image.Rect(0, 0, 1+random.Intn(30), 1+random.Intn(30)))
+		for y := 0; y < img.Rect.Dy(); y++ {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				img.SetNRGBA(x, y, color.NRGBA{uint8(i), uint8(x), uint8(y), 255})
+			}
+		}
+
+		result = append(result, Sprite{Name: fmt.Sprintf("sprite%d", i), Image: img})
+	}
+
+	return result
+}
+
+func TestPack(t *testing.T) {
+	for _, options := range []Options{
+		{MaxWidth: 128, MaxHeight: 128},
+		{MaxWidth: 128, MaxHeight: 128, Padding: 2},
+		{MaxWidth: 128, MaxHeight: 128, Extrude: 1},
+		{MaxWidth: 128, MaxHeight: 128, Padding: 1, Extrude: 2},
+	} {
+		t.Run(fmt.Sprintf("padding %d extrude %d", options.Padding, options.Extrude), func(t *testing.T) {
+			input := sprites(60)
+			atlas, err := Pack(input, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(atlas.Pages) < 2 {
+				t.Errorf("%d pages, the sprites don't fit on one", len(atlas.Pages))
+			}
+
+			for _, sprite := range input {
+				checkRegion(t, atlas, sprite, options)
+			}
+
+			for _, a := range input {
+				for _, b := range input {
+					regionA, regionB := atlas.Regions[a.Name], atlas.Regions[b.Name]
+					if a.Name == b.Name || regionA.Page != regionB.Page {
+						continue
+					}
+
+					// Extruded areas are at least Padding pixels apart
+					grown := rectangle(regionA).Inset(-options.Extrude - options.Padding)
+					if grown.Overlaps(rectangle(regionB).Inset(-options.Extrude)) {
+						t.Fatalf("%s %v and %s %v are closer than the padding", a.Name, rectangle(regionA), b.Name, rectangle(regionB))
+					}
+				}
+			}
+
+			checkPadding(t, atlas, options)
+		})
+	}
+}
+
+func rectangle(region Region) image.Rectangle {
+	return image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height)
+}
+
+// checkRegion compares the sprite to its region and the extruded border
+// around it to the sprite's edges.
+func checkRegion(t *testing.T, atlas *Atlas, sprite Sprite, options Options) {
+	t.Helper()
+
+	region := atlas.Regions[sprite.Name]
+	page := atlas.Pages[region.Page]
+	bounds := sprite.Image.Bounds()
+
+	if region.Width != bounds.Dx() || region.Height != bounds.Dy() {
+		t.Fatalf("%s region is %dx%d, image %dx%d", sprite.Name, region.Width, region.Height, bounds.Dx(), bounds.Dy())
+	}
+
+	if extruded := rectangle(region).Inset(-options.Extrude); !extruded.In(page.Rect) {
+		t.Fatalf("%s %v isn't on its %v page", sprite.Name, extruded, page.Rect)
+	}
+
+	clamp := func(value, low, high int) int { return max(low, min(high, value)) }
+
+	for y := -options.Extrude; y < region.Height+options.Extrude; y++ {
+		for x := -options.Extrude; x < region.Width+options.Extrude; x++ {
+			want := sprite.Image.At(clamp(x, 0, region.Width-1), clamp(y, 0, region.Height-1))
+			if got := page.At(region.X+x, region.Y+y); got != want {
+				t.Fatalf("%s pixel %d, %d is %v, want %v", sprite.Name, x, y, got, want)
+			}
+		}
+	}
+
+	uv := [4]float32{
+		float32(region.X) / float32(page.Rect.Dx()),
+		1 - float32(region.Y+region.Height)/float32(page.Rect.Dy()),
+		float32(region.X+region.Width) / float32(page.Rect.Dx()),
+		1 - float32(region.Y)/float32(page.Rect.Dy()),
+	}
+	if region.UV != uv {
+		t.Errorf("%s UV %v, want %v", sprite.Name, region.UV, uv)
+	}
+}
+
+// checkPadding makes sure pixels outside the extruded regions stay
+// transparent.
+func checkPadding(t *testing.T, atlas *Atlas, options Options) {
+	t.Helper()
+
+	for index, page := range atlas.Pages {
+		covered := image.NewAlpha(page.Rect)
+		for _, region := range atlas.Regions {
+			if region.Page != index {
+				continue
+			}
+
+			extruded := rectangle(region).Inset(-options.Extrude)
+			for y := extruded.Min.Y; y < extruded.Max.Y; y++ {
+				for x := extruded.Min.X; x < extruded.Max.X; x++ {
+					covered.SetAlpha(x, y, color.Alpha{255})
+				}
+			}
+		}
+
+		for y := page.Rect.Min.Y; y < page.Rect.Max.Y; y++ {
+			for x := page.Rect.Min.X; x < page.Rect.Max.X; x++ {
+				if covered.AlphaAt(x, y).A == 0 && page.NRGBAAt(x, y).A != 0 {
+					t.Fatalf("page %d pixel %d, %d outside every region is %v", index, x, y, page.NRGBAAt(x, y))
+				}
+			}
+		}
+	}
+}
+
+func TestPackOrderIndependent(t *testing.T) {
+	options := Options{MaxWidth: 128, MaxHeight: 128, Padding: 1, Extrude: 1}
+	input := sprites(60)
+
+	want, err := Pack(input, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	random := rand.New(rand.NewSource(3))
+	for i := 0; i < 5; i++ {
+		shuffled := append([]Sprite(nil), input...)
+		random.Shuffle(len(shuffled), func(a, b int) { shuffled[a], shuffled[b] = shuffled[b], shuffled[a] })
+
+		got, err := Pack(shuffled, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got.Regions, want.Regions) {
+			t.Fatalf("shuffle %d moved regions", i)
+		}
+
+		if len(got.Pages) != len(want.Pages) {
+			t.Fatalf("shuffle %d has %d pages, want %d", i, len(got.Pages), len(want.Pages))
+		}
+		for page := range want.Pages {
+			if got.Pages[page].Rect != want.Pages[page].Rect || !bytes.Equal(got.Pages[page].Pix, want.Pages[page].Pix) {
+				t.Fatalf("shuffle %d changed page %d", i, page)
+			}
+		}
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	small := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	tests := []struct {
+		name    string
+		sprites []Sprite
+		options Options
+	}{
+		{"duplicate names", []Sprite{{"a", small}, {"a", small}}, Options{}},
+		{"too large", []Sprite{{"a", image.NewNRGBA(image.Rect(0, 0, 20, 4))}}, Options{MaxWidth: 16, MaxHeight: 16}},
+		{"too large extruded", []Sprite{{"a", image.NewNRGBA(image.Rect(0, 0, 16, 4))}}, Options{MaxWidth: 16, MaxHeight: 16, Extrude: 1}},
+		{"negative padding", []Sprite{{"a", small}}, Options{Padding: -1}},
+		{"negative extrusion", []Sprite{{"a", small}}, Options{Extrude: -1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Pack(test.sprites, test.options); err == nil {
+				t.Errorf("Pack succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package atlas
+
+import (
+	"image"
+)
+
+// Packer places rectangles in a bin with the MaxRects algorithm, keeping the
+// list of maximal free rectangles and picking the one that leaves the
+// shortest leftover side.
+type Packer struct {
+	Width  int
+	Height int
+
+	free []image.Rectangle
+}
+
+func NewPacker(width int, height int) *Packer {
+	return &Packer{
+		Width:  width,
+		Height: height,
+		free:   []image.Rectangle{image.Rect(0, 0, width, height)},
+	}
+}
+
+// Insert reserves a width x height rectangle and returns it, or false when it
+// doesn't fit anywhere.
+func (packer *Packer) Insert(width int, height int) (image.Rectangle, bool) {
+	best := -1
+	bestShort, bestLong := 0, 0
+
+	for i, free := range packer.free {
+		leftoverX, leftoverY := free.Dx()-width, free.Dy()-height
+		if leftoverX < 0 || leftoverY < 0 {
+			continue
+		}
+
+		short, long := min(leftoverX, leftoverY), max(leftoverX, leftoverY)
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+
+	if best < 0 {
+		return image.Rectangle{}, false
+	}
+
+	placed := image.Rectangle{Min: packer.free[best].Min, Max: packer.free[best].Min.Add(image.Pt(width, height))}
+	packer.split(placed)
+
+	return placed, true
+}
+
+// split replaces the free rectangles overlapping placed by the up to four
+// maximal rectangles around it, then drops those contained in others.
+func (packer *Packer) split(placed image.Rectangle) {
+	var free []image.Rectangle
+
+	for _, rect := range packer.free {
+		if !rect.Overlaps(placed) {
+			free = append(free, rect)
+			continue
+		}
+
+		if placed.Min.X > rect.Min.X {
+			free = append(free, image.Rect(rect.Min.X, rect.Min.Y, placed.Min.X, rect.Max.Y))
+		}
+		if placed.Max.X < rect.Max.X {
+			free = append(free, image.Rect(placed.Max.X, rect.Min.Y, rect.Max.X, rect.Max.Y))
+		}
+		if placed.Min.Y > rect.Min.Y {
+			free = append(free, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, placed.Min.Y))
+		}
+		if placed.Max.Y < rect.Max.Y {
+			free = append(free, image.Rect(rect.Min.X, placed.Max.Y, rect.Max.X, rect.Max.Y))
+		}
+	}
+
+	packer.free = packer.free[:0]
+	for i, rect := range free {
+		contained := false
+		for j, other := range free {
+			// Of two equal rectangles the first one is kept
+			if i != j && rect.In(other) && (rect != other || j < i) {
+				contained = true
+				break
+			}
+		}
+
+		if !contained {
+			packer.free = append(packer.free, rect)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package texture
+
+import (
+	"fmt"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/texture/compressed"
+	"github.com/go-gl/example/texture/dds"
+	"github.com/go-gl/example/texture/ktx"
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+func loadCompressed(data []byte, options Options) (*Texture, error) {
+	image, err := parseCompressed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromCompressed(image, options)
+}
+
+// parseCompressed parses DDS or KTX data.
+func parseCompressed(data []byte) (*compressed.Image, error) {
+	if dds.Is(data) {
+		return dds.Parse(data)
+	}
+
+	return ktx.Parse(data)
+}
+
+// Supported reports whether the driver can sample format.
+func Supported(format compressed.Format) bool {
+	if len(format.Extensions) == 0 {
+		return true
+	}
+
+	for _, extension := range format.Extensions {
+		if glfw.ExtensionSupported(extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FromCompressed uploads block compressed data as is to a 2D texture, a cube
+// map or a 2D array texture. The file's mipmap levels are used, Mipmaps only
+// picks the default MinFilter since GL can't generate levels for compressed
+// formats. InternalFormat, ColorSpace, FlipY and Premultiply don't apply, the
+// format says whether the data is sRGB.
+func FromCompressed(image *compressed.Image, options Options) (*Texture, error) {
+	if !Supported(image.Format) {
+		return nil, fmt.Errorf("%s textures need one of %v, which the driver doesn't have", image.Format, image.Format.Extensions)
+	}
+
+	texture := &Texture{
+		Target: gl.TEXTURE_2D,
+		Width:  image.Width,
+		Height: image.Height,
+		Depth:  image.Layers,
+		Format: int32(image.Format.GL),
+	}
+
+	switch {
+	case image.Faces == 6 && image.Layers > 1:
+		return nil, fmt.Errorf("cube map arrays aren't supported")
+	case image.Faces == 6:
+		texture.Target = gl.TEXTURE_CUBE_MAP
+	case image.Layers > 1:
+		texture.Target = gl.TEXTURE_2D_ARRAY
+	}
+
+	gl.GenTextures(1, &texture.Id)
+	restore := texture.edit()
+
+	format := image.Format.GL
+	for i, level := range image.Levels {
+		width, height := int32(level.Width), int32(level.Height)
+
+		switch texture.Target {
+		case gl.TEXTURE_2D:
+			data := level.Images[0]
+			gl.CompressedTexImage2D(gl.TEXTURE_2D, int32(i), format, width, height, 0, int32(len(data)), gl.Ptr(data))
+
+		case gl.TEXTURE_CUBE_MAP:
+			for face, data := range level.Images {
+				gl.CompressedTexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(face), int32(i), format, width, height, 0, int32(len(data)), gl.Ptr(data))
+			}
+
+		case gl.TEXTURE_2D_ARRAY:
+			// Layers have to be contiguous, files don't all store them so
+			var data []byte
+			for _, layer := range level.Images {
+				data = append(data, layer...)
+			}
+			gl.CompressedTexImage3D(gl.TEXTURE_2D_ARRAY, int32(i), format, width, height, int32(image.Layers), 0, int32(len(data)), gl.Ptr(data))
+		}
+	}
+
+	gl.TexParameteri(texture.Target, gl.TEXTURE_MAX_LEVEL, int32(len(image.Levels)-1))
+	texture.levels = len(image.Levels)
+
+	if options.MinFilter == 0 && len(image.Levels) > 1 {
+		options.MinFilter = gl.LINEAR_MIPMAP_LINEAR
+	}
+	options.Mipmaps = false
+	texture.SetOptions(options)
+
+	restore()
+	gldebug.Check("texture.FromCompressed")
+
+	return texture, nil
+}
@@ -0,0 +1,197 @@
+// Package compressed describes block compressed texture data read from
+// container files, see the dds and ktx packages. It's pure Go, formats are
+// identified by their GL internal format enums.
+package compressed
+
+import (
+	"fmt"
+)
+
+// Format is a block compression format.
+type Format struct {
+	// GL is the internal format passed to glCompressedTexImage2D.
+	GL   uint32
+	Name string
+
+	BlockWidth  int
+	BlockHeight int
+	BlockBytes  int
+
+	// Extensions is the GL extensions providing the format, any one of them
+	// is enough. Empty for formats that are core in GL 3.3.
+	Extensions []string
+}
+
+var (
+	s3tc     = []string{"GL_EXT_texture_compression_s3tc"}
+	s3tcSRGB = []string{"GL_EXT_texture_sRGB", "GL_EXT_texture_compression_s3tc_srgb"}
+	bptc     = []string{"GL_ARB_texture_compression_bptc"}
+	etc2     = []string{"GL_ARB_ES3_compatibility"}
+	astc     = []string{"GL_KHR_texture_compression_astc_ldr"}
+)
+
+// GL enums of the supported formats.
+const (
+	BC1RGB       = 0x83f0
+	BC1RGBA      = 0x83f1
+	BC2          = 0x83f2
+	BC3          = 0x83f3
+	BC1SRGB      = 0x8c4c
+	BC1SRGBAlpha = 0x8c4d
+	BC2SRGB      = 0x8c4e
+	BC3SRGB      = 0x8c4f
+	BC4          = 0x8dbb
+	BC4Signed    = 0x8dbc
+	BC5          = 0x8dbd
+	BC5Signed    = 0x8dbe
+	BC6HSigned   = 0x8e8e
+	BC6H         = 0x8e8f
+	BC7          = 0x8e8c
+	BC7SRGB      = 0x8e8d
+
+	ETC2RGB        = 0x9274
+	ETC2SRGB       = 0x9275
+	ETC2RGBA1      = 0x9276
+	ETC2SRGBAlpha1 = 0x9277
+	ETC2RGBA       = 0x9278
+	ETC2SRGBAlpha  = 0x9279
+	EACR11         = 0x9270
+	EACR11Signed   = 0x9271
+	EACRG11        = 0x9272
+	EACRG11Signed  = 0x9273
+
+	ASTC4x4     = 0x93b0
+	ASTC4x4SRGB = 0x93d0
+)
+
+var formats = map[uint32]Format{}
+
+func init() {
+	add := func(gl uint32, name string, blockBytes int, extensions []string) {
+		formats[gl] = Format{GL: gl, Name: name, BlockWidth: 4, BlockHeight: 4, BlockBytes: blockBytes, Extensions: extensions}
+	}
+
+	add(BC1RGB, "BC1 RGB", 8, s3tc)
+	add(BC1RGBA, "BC1 RGBA", 8, s3tc)
+	add(BC2, "BC2", 16, s3tc)
+	add(BC3, "BC3", 16, s3tc)
+	add(BC1SRGB, "BC1 sRGB", 8, s3tcSRGB)
+	add(BC1SRGBAlpha, "BC1 sRGB alpha", 8, s3tcSRGB)
+	add(BC2SRGB, "BC2 sRGB", 16, s3tcSRGB)
+	add(BC3SRGB, "BC3 sRGB", 16, s3tcSRGB)
+	add(BC4, "BC4", 8, nil)
+	add(BC4Signed, "BC4 signed", 8, nil)
+	add(BC5, "BC5", 16, nil)
+	add(BC5Signed, "BC5 signed", 16, nil)
+	add(BC6HSigned, "BC6H signed float", 16, bptc)
+	add(BC6H, "BC6H unsigned float", 16, bptc)
+	add(BC7, "BC7", 16, bptc)
+	add(BC7SRGB, "BC7 sRGB", 16, bptc)
+
+	add(ETC2RGB, "ETC2 RGB", 8, etc2)
+	add(ETC2SRGB, "ETC2 sRGB", 8, etc2)
+	add(ETC2RGBA1, "ETC2 RGB A1", 8, etc2)
+	add(ETC2SRGBAlpha1, "ETC2 sRGB A1", 8, etc2)
+	add(ETC2RGBA, "ETC2 RGBA", 16, etc2)
+	add(ETC2SRGBAlpha, "ETC2 sRGB alpha", 16, etc2)
+	add(EACR11, "EAC R11", 8, etc2)
+	add(EACR11Signed, "EAC R11 signed", 8, etc2)
+	add(EACRG11, "EAC RG11", 16, etc2)
+	add(EACRG11Signed, "EAC RG11 signed", 16, etc2)
+
+	add(ASTC4x4, "ASTC 4x4", 16, astc)
+	add(ASTC4x4SRGB, "ASTC 4x4 sRGB", 16, astc)
+}
+
+// Lookup returns the format with GL internal format gl.
+func Lookup(gl uint32) (Format, bool) {
+	format, ok := formats[gl]
+	return format, ok
+}
+
+// MustLookup is Lookup for the package's own constants.
+func MustLookup(gl uint32) Format {
+	format, ok := formats[gl]
+	if !ok {
+		panic(fmt.Sprintf("compressed: unknown format 0x%x", gl))
+	}
+
+	return format
+}
+
+// Size is the number of bytes of an image of the given size, partial blocks
+// at the edges taking a whole block.
+func (format Format) Size(width int, height int) int {
+	blocksX := (width + format.BlockWidth - 1) / format.BlockWidth
+	blocksY := (height + format.BlockHeight - 1) / format.BlockHeight
+
+	return blocksX * blocksY * format.BlockBytes
+}
+
+func (format Format) String() string {
+	return format.Name
+}
+
+// Image is a texture with all its mipmap levels, array layers and cube faces.
+type Image struct {
+	Format Format
+	Width  int
+	Height int
+	// Layers is 1 unless the texture is an array.
+	Layers int
+	// Faces is 6 for cube maps, 1 otherwise.
+	Faces  int
+	Levels []Level
+}
+
+// Level is one mipmap level, Images has Layers * Faces images ordered by
+// layer then face, faces in +X, -X, +Y, -Y, +Z, -Z order.
+type Level struct {
+	Width  int
+	Height int
+	Images [][]byte
+}
+
+// maxDimension bounds sizes read from files, so corrupt headers can't make
+// size computations overflow or allocate absurd amounts.
+const maxDimension = 1 << 16
+
+// CheckSize validates dimensions and counts read from a file header and
+// returns the number of mipmap levels, a count of 0 meaning 1.
+func CheckSize(width int, height int, layers int, faces int, levels int) (int, error) {
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return 0, fmt.Errorf("invalid size %dx%d", width, height)
+	}
+
+	if layers < 1 || layers > 2048 {
+		return 0, fmt.Errorf("invalid layer count %d", layers)
+	}
+
+	if faces != 1 && faces != 6 {
+		return 0, fmt.Errorf("invalid face count %d", faces)
+	}
+
+	if faces == 6 && width != height {
+		return 0, fmt.Errorf("cube map faces aren't square, %dx%d", width, height)
+	}
+
+	if levels == 0 {
+		levels = 1
+	}
+
+	maxLevels := 1
+	for size := max(width, height); size > 1; size /= 2 {
+		maxLevels++
+	}
+
+	if levels < 1 || levels > maxLevels {
+		return 0, fmt.Errorf("invalid mipmap level count %d for %dx%d", levels, width, height)
+	}
+
+	return levels, nil
+}
+
+// LevelSize is the size of mipmap level level of a width x height image.
+func LevelSize(width int, height int, level int) (int, int) {
+	return max(width>>level, 1), max(height>>level, 1)
+}
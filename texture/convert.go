@@ -0,0 +1,208 @@
+package texture
+
+import (
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/go-gl/example/texture/hdr"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Pixel conversions done on the CPU before upload, they don't touch GL.
+
+// pixelData is an image ready for glTexImage2D.
+type pixelData struct {
+	width, height    int
+	format, dataType uint32
+	// internalFormat is used when Options doesn't set one.
+	internalFormat int32
+	// pixels is a []byte, []uint16 or []float32.
+	pixels interface{}
+}
+
+func prepare(img image.Image, options Options) pixelData {
+	switch img := img.(type) {
+	case *hdr.Image:
+		return prepareFloat(img, options)
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+		return prepare16(img, options)
+	}
+
+	return prepare8(img, options)
+}
+
+func prepare8(img image.Image, options Options) pixelData {
+	pixels := toNRGBA(img)
+	if pixels == img && (options.FlipY || options.Premultiply) {
+		pixels = &image.NRGBA{Pix: append([]byte(nil), pixels.Pix...), Stride: pixels.Stride, Rect: pixels.Rect}
+	}
+
+	if options.FlipY {
+		FlipRows(pixels.Pix, pixels.Stride)
+	}
+
+	if options.Premultiply {
+		Premultiply(pixels.Pix, options.ColorSpace == SRGB)
+	}
+
+	data := pixelData{
+		width:          pixels.Rect.Dx(),
+		height:         pixels.Rect.Dy(),
+		format:         gl.RGBA,
+		dataType:       gl.UNSIGNED_BYTE,
+		internalFormat: gl.RGBA8,
+		pixels:         pixels.Pix,
+	}
+
+	if options.ColorSpace == SRGB {
+		data.internalFormat = gl.SRGB8_ALPHA8
+	}
+
+	return data
+}
+
+// prepare16 keeps the full precision of 16-bit images, Go stores them big
+// endian and GL wants native uint16.
+func prepare16(img image.Image, options Options) pixelData {
+	bounds := img.Bounds()
+	nrgba := image.NewNRGBA64(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	pixels := make([]uint16, len(nrgba.Pix)/2)
+	for i := range pixels {
+		pixels[i] = binary.BigEndian.Uint16(nrgba.Pix[i*2:])
+	}
+
+	if options.FlipY {
+		FlipRows(pixels, bounds.Dx()*4)
+	}
+
+	if options.Premultiply {
+		for i := 0; i+3 < len(pixels); i += 4 {
+			alpha := uint32(pixels[i+3])
+			for c := i; c < i+3; c++ {
+				pixels[c] = uint16((uint32(pixels[c])*alpha + 0x7fff) / 0xffff)
+			}
+		}
+	}
+
+	return pixelData{
+		width:          bounds.Dx(),
+		height:         bounds.Dy(),
+		format:         gl.RGBA,
+		dataType:       gl.UNSIGNED_SHORT,
+		internalFormat: gl.RGBA16,
+		pixels:         pixels,
+	}
+}
+
+// prepareFloat uploads HDR images as they are, they have no alpha to
+// premultiply.
+func prepareFloat(img *hdr.Image, options Options) pixelData {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+
+	pixels := make([]float32, 0, width*height*3)
+	for y := 0; y < height; y++ {
+		pixels = append(pixels, img.Pix[y*img.Stride:y*img.Stride+width*3]...)
+	}
+
+	if options.FlipY {
+		FlipRows(pixels, width*3)
+	}
+
+	return pixelData{
+		width:          width,
+		height:         height,
+		format:         gl.RGB,
+		dataType:       gl.FLOAT,
+		internalFormat: gl.RGB32F,
+		pixels:         pixels,
+	}
+}
+
+// toNRGBA copies img to a tightly packed image with straight alpha and its
+// origin at 0, 0. Images that already are one are returned as is.
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	if nrgba, ok := img.(*image.NRGBA); ok && bounds.Min == (image.Point{}) && nrgba.Stride == bounds.Dx()*4 {
+		return nrgba
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	return nrgba
+}
+
+// FlipRows reverses the order of rows in pix in place, stride being the
+// number of elements per row. Go images start at the top row and GL textures
+// at the bottom one, flipping makes V = 0 the bottom of the image. Elements
+// past the last whole row stay where they are.
+func FlipRows[T any](pix []T, stride int) {
+	if stride <= 0 {
+		return
+	}
+
+	rows := len(pix) / stride
+	temporary := make([]T, stride)
+
+	for top, bottom := 0, rows-1; top < bottom; top, bottom = top+1, bottom-1 {
+		topRow := pix[top*stride : (top+1)*stride]
+		bottomRow := pix[bottom*stride : (bottom+1)*stride]
+
+		copy(temporary, topRow)
+		copy(topRow, bottomRow)
+		copy(bottomRow, temporary)
+	}
+}
+
+// Premultiply multiplies the color of 8-bit RGBA pixels by their alpha in
+// place. With srgb the colors are sRGB encoded and multiplied in linear space,
+// so blending premultiplied sRGB textures matches blending straight ones.
+func Premultiply(pix []byte, srgb bool) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		alpha := pix[i+3]
+		if alpha == 255 {
+			continue
+		}
+
+		for c := i; c < i+3; c++ {
+			if srgb {
+				pix[c] = LinearToSRGB(SRGBToLinear(pix[c]) * float32(alpha) / 255)
+			} else {
+				pix[c] = uint8((uint32(pix[c])*uint32(alpha) + 127) / 255)
+			}
+		}
+	}
+}
+
+var srgbToLinear = func() (table [256]float32) {
+	for i := range table {
+		value := float64(i) / 255
+		if value <= 0.04045 {
+			table[i] = float32(value / 12.92)
+		} else {
+			table[i] = float32(math.Pow((value+0.055)/1.055, 2.4))
+		}
+	}
+	return table
+}()
+
+// SRGBToLinear decodes an 8-bit sRGB value to linear [0, 1].
+func SRGBToLinear(value uint8) float32 {
+	return srgbToLinear[value]
+}
+
+// LinearToSRGB encodes a linear value, clamped to [0, 1], to 8-bit sRGB.
+func LinearToSRGB(value float32) uint8 {
+	v := math.Max(0, math.Min(1, float64(value)))
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(v * 255))
+}
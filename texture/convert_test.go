@@ -0,0 +1,110 @@
+package texture
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFlipRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		pix    []int
+		stride int
+		want   []int
+	}{
+		{"empty", nil, 2, nil},
+		{"one row", []int{1, 2, 3}, 3, []int{1, 2, 3}},
+		{"even rows", []int{1, 2, 3, 4, 5, 6, 7, 8}, 2, []int{7, 8, 5, 6, 3, 4, 1, 2}},
+		{"odd rows", []int{1, 2, 3, 4, 5, 6}, 2, []int{5, 6, 3, 4, 1, 2}},
+		{"partial last row", []int{1, 2, 3, 4, 5}, 2, []int{3, 4, 1, 2, 5}},
+		{"zero stride", []int{1, 2, 3}, 0, []int{1, 2, 3}},
+		{"negative stride", []int{1, 2, 3}, -1, []int{1, 2, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			FlipRows(test.pix, test.stride)
+
+			if !reflect.DeepEqual(test.pix, test.want) {
+				t.Errorf("got %v, want %v", test.pix, test.want)
+			}
+		})
+	}
+}
+
+func TestPremultiply(t *testing.T) {
+	tests := []struct {
+		name string
+		pix  []byte
+		srgb bool
+		want []byte
+	}{
+		{"opaque", []byte{200, 100, 50, 255}, false, []byte{200, 100, 50, 255}},
+		{"transparent", []byte{200, 100, 50, 0}, false, []byte{0, 0, 0, 0}},
+		{"half", []byte{200, 100, 50, 128}, false, []byte{100, 50, 25, 128}},
+		{"white half", []byte{255, 255, 255, 128}, false, []byte{128, 128, 128, 128}},
+		{"srgb opaque", []byte{200, 100, 50, 255}, true, []byte{200, 100, 50, 255}},
+		{"srgb transparent", []byte{200, 100, 50, 0}, true, []byte{0, 0, 0, 0}},
+		// Half coverage in linear light is brighter than half the sRGB value
+		{"srgb half", []byte{200, 100, 255, 128}, true, []byte{147, 72, 188, 128}},
+		{"several pixels", []byte{255, 0, 0, 0, 0, 255, 0, 255}, false, []byte{0, 0, 0, 0, 0, 255, 0, 255}},
+		{"trailing bytes", []byte{10, 20, 30, 0, 40, 50}, false, []byte{0, 0, 0, 0, 40, 50}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Premultiply(test.pix, test.srgb)
+
+			if !reflect.DeepEqual(test.pix, test.want) {
+				t.Errorf("got %v, want %v", test.pix, test.want)
+			}
+		})
+	}
+}
+
+func TestSRGBToLinear(t *testing.T) {
+	tests := []struct {
+		value uint8
+		want  float32
+	}{
+		{0, 0},
+		{10, 0.0030353},
+		{128, 0.2158605},
+		{255, 1},
+	}
+
+	for _, test := range tests {
+		if got := SRGBToLinear(test.value); math.Abs(float64(got-test.want)) > 1e-6 {
+			t.Errorf("SRGBToLinear(%d) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestLinearToSRGB(t *testing.T) {
+	tests := []struct {
+		value float32
+		want  uint8
+	}{
+		{0, 0},
+		{0.0031308, 10},
+		{0.5, 188},
+		{1, 255},
+		{-1, 0},
+		{2, 255},
+	}
+
+	for _, test := range tests {
+		if got := LinearToSRGB(test.value); got != test.want {
+			t.Errorf("LinearToSRGB(%v) = %d, want %d", test.value, got, test.want)
+		}
+	}
+}
+
+func TestSRGBRoundTrip(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		if got := LinearToSRGB(SRGBToLinear(uint8(i))); got != uint8(i) {
+			t.Errorf("%d round trips to %d", i, got)
+		}
+	}
+}
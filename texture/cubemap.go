@@ -0,0 +1,336 @@
+package texture
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/texture/dds"
+	"github.com/go-gl/example/texture/hdr"
+	"github.com/go-gl/example/texture/ktx"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Cube map faces are in GL order, +X, -X, +Y, -Y, +Z, -Z. Each face is seen
+// from inside the cube as GL samples it, the first row of the image at the
+// top, so face images are never flipped.
+
+// LoadCubemap loads a cube map from six face images in +X, -X, +Y, -Y, +Z,
+// -Z order, or from a single file: a DDS or KTX cube map, a cross of faces
+// when the image is 4:3 or 3:4 (see CrossFaces) or an equirectangular
+// panorama when it's 2:1 (see EquirectFaces).
+func LoadCubemap(paths []string, options Options) (*Texture, error) {
+	if len(paths) != 1 && len(paths) != 6 {
+		return nil, fmt.Errorf("texture: cube maps need 1 or 6 images, got %d", len(paths))
+	}
+
+	var faces [6]image.Image
+
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(paths) == 1 && (dds.Is(data) || ktx.Is(data)) {
+			texture, err := loadCompressed(data, options)
+			if err != nil {
+				return nil, fmt.Errorf("texture: %s: %v", path, err)
+			}
+			if texture.Target != gl.TEXTURE_CUBE_MAP {
+				texture.Delete()
+				return nil, fmt.Errorf("texture: %s: not a cube map", path)
+			}
+			return texture, nil
+		}
+
+		img, err := decode(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("texture: %s: %v", path, err)
+		}
+
+		if len(paths) == 6 {
+			faces[i] = img
+			continue
+		}
+
+		if bounds := img.Bounds(); bounds.Dx() == 2*bounds.Dy() {
+			// Faces are a quarter of the panorama's width
+			if bounds.Dx() < 4 {
+				return nil, fmt.Errorf("texture: %s: panorama of %dx%d is smaller than 4x2", path, bounds.Dx(), bounds.Dy())
+			}
+			faces = EquirectFaces(img, bounds.Dx()/4)
+		} else if faces, err = CrossFaces(img); err != nil {
+			return nil, fmt.Errorf("texture: %s: %v", path, err)
+		}
+	}
+
+	texture, err := CubemapFromImages(faces, options)
+	if err != nil {
+		return nil, fmt.Errorf("texture: %s: %v", paths[0], err)
+	}
+
+	return texture, nil
+}
+
+// CubemapFromImages uploads six square faces of the same size to a new cube
+// map, converted as FromImage does. FlipY is ignored. Wrap modes default to
+// gl.CLAMP_TO_EDGE, which with gl.TEXTURE_CUBE_MAP_SEAMLESS enabled hides the
+// seams between faces.
+func CubemapFromImages(faces [6]image.Image, options Options) (*Texture, error) {
+	size := faces[0].Bounds().Size()
+	if size.X != size.Y {
+		return nil, fmt.Errorf("cube map faces aren't square, %dx%d", size.X, size.Y)
+	}
+	if size.X == 0 {
+		return nil, fmt.Errorf("cube map faces are empty")
+	}
+
+	for i, face := range faces {
+		if face.Bounds().Size() != size {
+			return nil, fmt.Errorf("cube map face %d is %dx%d, expected %dx%d", i, face.Bounds().Dx(), face.Bounds().Dy(), size.X, size.Y)
+		}
+	}
+
+	options.FlipY = false
+
+	texture := &Texture{
+		Target: gl.TEXTURE_CUBE_MAP,
+		Width:  size.X,
+		Height: size.Y,
+		Depth:  1,
+	}
+
+	gl.GenTextures(1, &texture.Id)
+	restore := texture.edit()
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+
+	for i, face := range faces {
+		data := prepare(face, options)
+
+		if texture.Format == 0 {
+			texture.Format = orDefault(options.InternalFormat, data.internalFormat)
+		}
+
+		gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, texture.Format, int32(size.X), int32(size.Y), 0, data.format, data.dataType, gl.Ptr(data.pixels))
+	}
+
+	texture.SetOptions(options)
+
+	restore()
+	gldebug.Check("texture.CubemapFromImages")
+
+	return texture, nil
+}
+
+// CrossFaces cuts the faces out of a cross layout. Horizontal crosses are 4:3
+// with -X, +Z, +X, -Z along the middle row, vertical ones are 3:4 with -Z
+// upside down at the bottom. +Y is above +Z and -Y below it in both.
+func CrossFaces(img image.Image) ([6]image.Image, error) {
+	// Face positions in face sized cells, in GL face order
+	horizontal := [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}
+	vertical := [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var cells [6]image.Point
+	var size int
+	switch {
+	case width*3 == height*4 && width%4 == 0:
+		cells, size = horizontal, width/4
+	case width*4 == height*3 && width%3 == 0:
+		cells, size = vertical, width/3
+	default:
+		return [6]image.Image{}, fmt.Errorf("%dx%d isn't a 4:3 or 3:4 cross of square faces", width, height)
+	}
+
+	source := newTexels(img)
+
+	var faces [6]image.Image
+	for i, cell := range cells {
+		var set func(x, y int, color [4]float32)
+		faces[i], set = newFace(img, size)
+
+		// The bottom face of a vertical cross is rotated half a turn
+		rotated := i == 5 && cells == vertical
+
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				sourceX, sourceY := x, y
+				if rotated {
+					sourceX, sourceY = size-1-x, size-1-y
+				}
+				set(x, y, source.at(cell.X*size+sourceX, cell.Y*size+sourceY))
+			}
+		}
+	}
+
+	return faces, nil
+}
+
+// EquirectFaces projects an equirectangular panorama to cube faces of
+// size x size pixels, sampling it bilinearly. The panorama's center looks
+// down -Z with +Y up, its left and right edges meeting behind at +Z. Faces
+// keep the precision of img: *hdr.Image gives *hdr.Image faces, 16-bit images
+// *image.NRGBA64 ones and others *image.NRGBA.
+func EquirectFaces(img image.Image, size int) [6]image.Image {
+	source := newTexels(img)
+
+	var faces [6]image.Image
+	var wait sync.WaitGroup
+
+	for i := range faces {
+		var set func(x, y int, color [4]float32)
+		faces[i], set = newFace(img, size)
+
+		wait.Add(1)
+		go func(face int) {
+			defer wait.Done()
+
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					s := 2*(float64(x)+0.5)/float64(size) - 1
+					t := 2*(float64(y)+0.5)/float64(size) - 1
+					dx, dy, dz := faceDirection(face, s, t)
+
+					longitude := math.Atan2(dx, -dz)
+					latitude := math.Atan2(dy, math.Hypot(dx, dz))
+
+					set(x, y, source.bilinear(0.5+longitude/(2*math.Pi), 0.5-latitude/math.Pi))
+				}
+			}
+		}(i)
+	}
+
+	wait.Wait()
+
+	return faces
+}
+
+// faceDirection is the direction a face's texel at s, t in [-1, 1] is looked
+// up with, inverting the face selection of the GL spec.
+func faceDirection(face int, s float64, t float64) (float64, float64, float64) {
+	switch face {
+	case 0:
+		return 1, -t, -s
+	case 1:
+		return -1, -t, s
+	case 2:
+		return s, 1, t
+	case 3:
+		return s, -1, -t
+	case 4:
+		return s, -t, 1
+	}
+
+	return -s, -t, -1
+}
+
+// texels reads the pixels of an image as floats, straight alpha in [0, 1] and
+// unbounded for HDR images, with 0, 0 at the top left.
+type texels struct {
+	width, height int
+	at            func(x, y int) [4]float32
+}
+
+func newTexels(img image.Image) texels {
+	bounds := img.Bounds()
+	source := texels{width: bounds.Dx(), height: bounds.Dy()}
+
+	switch img := img.(type) {
+	case *hdr.Image:
+		source.at = func(x, y int) [4]float32 {
+			r, g, b := img.RGB(bounds.Min.X+x, bounds.Min.Y+y)
+			return [4]float32{r, g, b, 1}
+		}
+
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+		nrgba := image.NewNRGBA64(image.Rect(0, 0, source.width, source.height))
+		draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+		source.at = func(x, y int) [4]float32 {
+			pixel := nrgba.Pix[y*nrgba.Stride+x*8:]
+
+			var color [4]float32
+			for c := range color {
+				color[c] = float32(uint16(pixel[c*2])<<8|uint16(pixel[c*2+1])) / 0xffff
+			}
+			return color
+		}
+
+	default:
+		nrgba := toNRGBA(img)
+
+		source.at = func(x, y int) [4]float32 {
+			pixel := nrgba.Pix[y*nrgba.Stride+x*4:]
+			return [4]float32{float32(pixel[0]) / 255, float32(pixel[1]) / 255, float32(pixel[2]) / 255, float32(pixel[3]) / 255}
+		}
+	}
+
+	return source
+}
+
+// bilinear samples at u, v in [0, 1], wrapping around horizontally and
+// clamping vertically as a panorama needs.
+func (source texels) bilinear(u float64, v float64) [4]float32 {
+	x := u*float64(source.width) - 0.5
+	y := min(max(v*float64(source.height)-0.5, 0), float64(source.height-1))
+
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := float32(x-x0), float32(y-y0)
+
+	wrap := func(x int) int {
+		return (x%source.width + source.width) % source.width
+	}
+	left, right := wrap(int(x0)), wrap(int(x0)+1)
+	top, bottom := int(y0), min(int(y0)+1, source.height-1)
+
+	topLeft, topRight := source.at(left, top), source.at(right, top)
+	bottomLeft, bottomRight := source.at(left, bottom), source.at(right, bottom)
+
+	var color [4]float32
+	for c := range color {
+		upper := topLeft[c] + (topRight[c]-topLeft[c])*fx
+		lower := bottomLeft[c] + (bottomRight[c]-bottomLeft[c])*fx
+		color[c] = upper + (lower-upper)*fy
+	}
+
+	return color
+}
+
+// newFace creates a size x size image with the precision of source and a
+// function setting its pixels from texels values.
+func newFace(source image.Image, size int) (image.Image, func(x, y int, color [4]float32)) {
+	rect := image.Rect(0, 0, size, size)
+
+	switch source.(type) {
+	case *hdr.Image:
+		face := hdr.NewImage(rect)
+		return face, func(x, y int, color [4]float32) {
+			copy(face.Pix[y*face.Stride+x*3:], color[:3])
+		}
+
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+		face := image.NewNRGBA64(rect)
+		return face, func(x, y int, color [4]float32) {
+			pixel := face.Pix[y*face.Stride+x*8:]
+			for c, value := range color {
+				value16 := uint16(math.Round(float64(min(max(value, 0), 1)) * 0xffff))
+				pixel[c*2], pixel[c*2+1] = uint8(value16>>8), uint8(value16)
+			}
+		}
+	}
+
+	face := image.NewNRGBA(rect)
+	return face, func(x, y int, color [4]float32) {
+		pixel := face.Pix[y*face.Stride+x*4:]
+		for c, value := range color {
+			pixel[c] = uint8(math.Round(float64(min(max(value, 0), 1)) * 255))
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package dds parses DirectDraw Surface files holding block compressed
+// textures: BC1 to BC7 through legacy FourCC codes or the DX10 header, with
+// mipmap chains, cube maps and arrays. Volume textures and uncompressed
+// formats aren't supported.
+package dds
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/go-gl/example/texture/compressed"
+)
+
+const (
+	magic = 0x20534444 // "DDS "
+
+	headerSize      = 124
+	pixelFormatSize = 32
+
+	pixelFormatFourCC = 0x4
+
+	caps2Cubemap    = 0x200
+	caps2AllFaces   = 0xfc00
+	caps2Volume     = 0x200000
+	dx10MiscCube    = 0x4
+	dx10Texture2D   = 3
+	fourCCDX10      = "DX10"
+	dx10HeaderSize  = 20
+	legacyDataStart = 4 + headerSize
+)
+
+var fourCCFormats = map[string]uint32{
+	"DXT1": compressed.BC1RGBA,
+	"DXT2": compressed.BC2,
+	"DXT3": compressed.BC2,
+	"DXT4": compressed.BC3,
+	"DXT5": compressed.BC3,
+	"ATI1": compressed.BC4,
+	"BC4U": compressed.BC4,
+	"BC4S": compressed.BC4Signed,
+	"ATI2": compressed.BC5,
+	"BC5U": compressed.BC5,
+	"BC5S": compressed.BC5Signed,
+}
+
+// dxgiFormats maps DXGI_FORMAT values, typeless formats read as unorm.
+var dxgiFormats = map[uint32]uint32{
+	70: compressed.BC1RGBA, 71: compressed.BC1RGBA, 72: compressed.BC1SRGBAlpha,
+	73: compressed.BC2, 74: compressed.BC2, 75: compressed.BC2SRGB,
+	76: compressed.BC3, 77: compressed.BC3, 78: compressed.BC3SRGB,
+	79: compressed.BC4, 80: compressed.BC4, 81: compressed.BC4Signed,
+	82: compressed.BC5, 83: compressed.BC5, 84: compressed.BC5Signed,
+	94: compressed.BC6H, 95: compressed.BC6H, 96: compressed.BC6HSigned,
+	97: compressed.BC7, 98: compressed.BC7, 99: compressed.BC7SRGB,
+}
+
+// Is reports whether data starts like a DDS file.
+func Is(data []byte) bool {
+	return len(data) >= 4 && binary.LittleEndian.Uint32(data) == magic
+}
+
+// Parse reads a DDS file. The returned images slice data.
+func Parse(data []byte) (*compressed.Image, error) {
+	if !Is(data) {
+		return nil, fmt.Errorf("dds: not a DDS file")
+	}
+
+	if len(data) < legacyDataStart {
+		return nil, fmt.Errorf("dds: header truncated")
+	}
+
+	header := data[4:legacyDataStart]
+	field := func(offset int) uint32 { return binary.LittleEndian.Uint32(header[offset:]) }
+
+	if field(0) != headerSize {
+		return nil, fmt.Errorf("dds: invalid header size %d", field(0))
+	}
+
+	height, width := int(field(8)), int(field(12))
+	levels := int(field(24))
+	pixelFormat := header[72 : 72+pixelFormatSize]
+	caps2 := field(108)
+
+	if binary.LittleEndian.Uint32(pixelFormat) != pixelFormatSize {
+		return nil, fmt.Errorf("dds: invalid pixel format size %d", binary.LittleEndian.Uint32(pixelFormat))
+	}
+	if binary.LittleEndian.Uint32(pixelFormat[4:])&pixelFormatFourCC == 0 {
+		return nil, fmt.Errorf("dds: uncompressed formats aren't supported")
+	}
+	if caps2&caps2Volume != 0 {
+		return nil, fmt.Errorf("dds: volume textures aren't supported")
+	}
+
+	fourCC := string(pixelFormat[8:12])
+	start := legacyDataStart
+	layers, faces := 1, 1
+
+	var glFormat uint32
+	if fourCC == fourCCDX10 {
+		if len(data) < start+dx10HeaderSize {
+			return nil, fmt.Errorf("dds: DX10 header truncated")
+		}
+
+		dx10 := data[start : start+dx10HeaderSize]
+		start += dx10HeaderSize
+
+		dxgiFormat := binary.LittleEndian.Uint32(dx10)
+		format, ok := dxgiFormats[dxgiFormat]
+		if !ok {
+			return nil, fmt.Errorf("dds: unsupported DXGI format %d", dxgiFormat)
+		}
+		glFormat = format
+
+		if dimension := binary.LittleEndian.Uint32(dx10[4:]); dimension != dx10Texture2D {
+			return nil, fmt.Errorf("dds: unsupported resource dimension %d", dimension)
+		}
+
+		if binary.LittleEndian.Uint32(dx10[8:])&dx10MiscCube != 0 {
+			faces = 6
+		}
+		layers = int(binary.LittleEndian.Uint32(dx10[12:]))
+	} else {
+		format, ok := fourCCFormats[fourCC]
+		if !ok {
+			return nil, fmt.Errorf("dds: unsupported FourCC %q", fourCC)
+		}
+		glFormat = format
+
+		if caps2&caps2Cubemap != 0 {
+			if caps2&caps2AllFaces != caps2AllFaces {
+				return nil, fmt.Errorf("dds: cube maps with missing faces aren't supported")
+			}
+			faces = 6
+		}
+	}
+
+	levels, err := compressed.CheckSize(width, height, layers, faces, levels)
+	if err != nil {
+		return nil, fmt.Errorf("dds: %v", err)
+	}
+
+	image := &compressed.Image{
+		Format: compressed.MustLookup(glFormat),
+		Width:  width,
+		Height: height,
+		Layers: layers,
+		Faces:  faces,
+		Levels: make([]compressed.Level, levels),
+	}
+
+	for level := range image.Levels {
+		image.Levels[level].Width, image.Levels[level].Height = compressed.LevelSize(width, height, level)
+	}
+
+	// DDS stores each face's whole mip chain, then the next face
+	offset := start
+	for i := 0; i < layers*faces; i++ {
+		for level := range image.Levels {
+			size := image.Format.Size(image.Levels[level].Width, image.Levels[level].Height)
+			if size > len(data)-offset {
+				return nil, fmt.Errorf("dds: data truncated in image %d level %d", i, level)
+			}
+
+			image.Levels[level].Images = append(image.Levels[level].Images, data[offset:offset+size])
+			offset += size
+		}
+	}
+
+	return image, nil
+}
@@ -0,0 +1,72 @@
+package dds
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// file builds a DDS file of width x height with the given FourCC and mipmap
+// levels. dx10 is appended as the DX10 header, followed by data zero bytes of
+// pixels.
+func file(width, height, levels int, fourCC string, caps2 uint32, dx10 []uint32, data int) []byte {
+	header := make([]byte, legacyDataStart)
+	put := func(offset int, value uint32) { binary.LittleEndian.PutUint32(header[offset:], value) }
+
+	put(0, magic)
+	put(4, headerSize)
+	put(4+8, uint32(height))
+	put(4+12, uint32(width))
+	put(4+24, uint32(levels))
+	put(4+72, pixelFormatSize)
+	put(4+76, pixelFormatFourCC)
+	copy(header[4+80:], fourCC)
+	put(4+108, caps2)
+
+	for _, value := range dx10 {
+		header = binary.LittleEndian.AppendUint32(header, value)
+	}
+
+	return append(header, make([]byte, data)...)
+}
+
+func TestParse(t *testing.T) {
+	image, err := Parse(file(8, 4, 2, "DXT5", 0, nil, 32+16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if image.Width != 8 || image.Height != 4 || len(image.Levels) != 2 || image.Faces != 1 {
+		t.Errorf("got %dx%d, %d levels, %d faces", image.Width, image.Height, len(image.Levels), image.Faces)
+	}
+
+	if _, err := Parse(file(8, 4, 2, "DXT5", 0, nil, 32+15)); err == nil {
+		t.Errorf("truncated data parsed")
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(file(4, 4, 1, "DXT1", 0, nil, 8))
+	f.Add(file(16, 8, 3, "DXT5", 0, nil, 128+32+16))
+	f.Add(file(4, 4, 1, "DXT1", caps2Cubemap|caps2AllFaces, nil, 6*8))
+	f.Add(file(8, 8, 0, fourCCDX10, 0, []uint32{98, dx10Texture2D, 0, 2, 0}, 2*64))
+	f.Add(file(4, 4, 1, fourCCDX10, 0, []uint32{71, dx10Texture2D, dx10MiscCube, 1, 0}, 6*8))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		image, err := Parse(data)
+		if err != nil {
+			return
+		}
+
+		for level, mip := range image.Levels {
+			if len(mip.Images) != image.Layers*image.Faces {
+				t.Fatalf("level %d has %d images, want %d", level, len(mip.Images), image.Layers*image.Faces)
+			}
+
+			for _, data := range mip.Images {
+				if len(data) != image.Format.Size(mip.Width, mip.Height) {
+					t.Fatalf("level %d image of %d bytes, want %d", level, len(data), image.Format.Size(mip.Width, mip.Height))
+				}
+			}
+		}
+	})
+}
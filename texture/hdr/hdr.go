@@ -0,0 +1,273 @@
+// Package hdr decodes Radiance HDR (RGBE) images to floating point.
+//
+// Importing the package registers the format with the image package. Only
+// 32-bit_rle_rgbe images with the standard -Y H +X W orientation or its
+// vertical flip are supported.
+package hdr
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Image holds linear RGB values, unbounded unlike other image types.
+type Image struct {
+	// Pix holds R, G, B triples, rows from the top.
+	Pix []float32
+	// Stride is the number of floats between rows.
+	Stride int
+	Rect   image.Rectangle
+}
+
+func NewImage(r image.Rectangle) *Image {
+	return &Image{Pix: make([]float32, r.Dx()*r.Dy()*3), Stride: r.Dx() * 3, Rect: r}
+}
+
+func (img *Image) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (img *Image) Bounds() image.Rectangle {
+	return img.Rect
+}
+
+// At clamps the color to [0, 1], use RGB for the actual values.
+func (img *Image) At(x, y int) color.Color {
+	r, g, b := img.RGB(x, y)
+	clamp := func(value float32) uint16 {
+		return uint16(math.Max(0, math.Min(1, float64(value)))*0xffff + 0.5)
+	}
+
+	return color.RGBA64{clamp(r), clamp(g), clamp(b), 0xffff}
+}
+
+func (img *Image) RGB(x, y int) (float32, float32, float32) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return 0, 0, 0
+	}
+
+	i := (y-img.Rect.Min.Y)*img.Stride + (x-img.Rect.Min.X)*3
+	return img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+}
+
+func init() {
+	image.RegisterFormat("hdr", "#?", Decode, DecodeConfig)
+}
+
+// Bounds on the size read from the header: 16384x16384 pixels, the largest
+// texture most GPUs take, and sides up to what a scanline buffer should cost.
+const (
+	maxPixels = 16384 * 16384
+	maxSide   = 1 << 16
+)
+
+type header struct {
+	width, height int
+	bottomUp      bool
+}
+
+func readHeader(r *bufio.Reader) (header, error) {
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "#?") {
+		return header{}, fmt.Errorf("hdr: missing #? signature")
+	}
+
+	// Variables up to an empty line, then the resolution
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return header{}, fmt.Errorf("hdr: header: %v", err)
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		if format, ok := strings.CutPrefix(line, "FORMAT="); ok && format != "32-bit_rle_rgbe" {
+			return header{}, fmt.Errorf("hdr: unsupported format %s", format)
+		}
+	}
+
+	line, err = r.ReadString('\n')
+	if err != nil {
+		return header{}, fmt.Errorf("hdr: resolution: %v", err)
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 4 || fields[2] != "+X" || (fields[0] != "-Y" && fields[0] != "+Y") {
+		return header{}, fmt.Errorf("hdr: unsupported resolution %q", strings.TrimSpace(line))
+	}
+
+	h := header{bottomUp: fields[0] == "+Y"}
+	h.height, err = strconv.Atoi(fields[1])
+	if err == nil {
+		h.width, err = strconv.Atoi(fields[3])
+	}
+	if err != nil || h.width <= 0 || h.height <= 0 {
+		return header{}, fmt.Errorf("hdr: invalid resolution %q", strings.TrimSpace(line))
+	}
+
+	if h.width > maxSide || h.height > maxSide || h.width*h.height > maxPixels {
+		return header{}, fmt.Errorf("hdr: image of %dx%d pixels is too large", h.width, h.height)
+	}
+
+	return h, nil
+}
+
+// DecodeConfig returns the size of an HDR image without decoding it.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := readHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	return image.Config{ColorModel: color.RGBA64Model, Width: h.width, Height: h.height}, nil
+}
+
+// Decode reads an HDR image as an *Image.
+func Decode(r io.Reader) (image.Image, error) {
+	reader := bufio.NewReader(r)
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pix grows with the scanlines read, so a header claiming a huge image
+	// only costs as much memory as the file actually holds
+	img := &Image{Stride: h.width * 3, Rect: image.Rect(0, 0, h.width, h.height)}
+	scanline := make([]byte, h.width*4)
+
+	for y := 0; y < h.height; y++ {
+		if err := readScanline(reader, scanline); err != nil {
+			return nil, fmt.Errorf("hdr: scanline %d: %v", y, err)
+		}
+
+		start := len(img.Pix)
+		img.Pix = slices.Grow(img.Pix, img.Stride)[:start+img.Stride]
+
+		pix := img.Pix[start:]
+		for x := 0; x < h.width; x++ {
+			rgbe := scanline[x*4 : x*4+4]
+			if rgbe[3] == 0 {
+				pix[x*3], pix[x*3+1], pix[x*3+2] = 0, 0, 0
+				continue
+			}
+
+			scale := float32(math.Ldexp(1, int(rgbe[3])-(128+8)))
+			pix[x*3] = (float32(rgbe[0]) + 0.5) * scale
+			pix[x*3+1] = (float32(rgbe[1]) + 0.5) * scale
+			pix[x*3+2] = (float32(rgbe[2]) + 0.5) * scale
+		}
+	}
+
+	if h.bottomUp {
+		for top, bottom := 0, h.height-1; top < bottom; top, bottom = top+1, bottom-1 {
+			a := img.Pix[top*img.Stride : (top+1)*img.Stride]
+			b := img.Pix[bottom*img.Stride : (bottom+1)*img.Stride]
+			for i := range a {
+				a[i], b[i] = b[i], a[i]
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// readScanline reads one row of RGBE pixels, either flat, in the old run
+// length encoding or in the newer one encoding each channel separately.
+func readScanline(r *bufio.Reader, scanline []byte) error {
+	width := len(scanline) / 4
+
+	start, err := r.Peek(4)
+	if err != nil {
+		return err
+	}
+
+	if width < 8 || width > 0x7fff || start[0] != 2 || start[1] != 2 || start[2]&0x80 != 0 {
+		return readOldScanline(r, scanline)
+	}
+
+	if int(start[2])<<8|int(start[3]) != width {
+		return fmt.Errorf("encoded width %d doesn't match %d", int(start[2])<<8|int(start[3]), width)
+	}
+	r.Discard(4)
+
+	for channel := 0; channel < 4; channel++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+
+			run := count > 128
+			if run {
+				count -= 128
+			}
+			if count == 0 || x+int(count) > width {
+				return fmt.Errorf("bad run length %d", count)
+			}
+
+			value, err := r.ReadByte()
+			for i := 0; i < int(count) && err == nil; i++ {
+				scanline[(x+i)*4+channel] = value
+				if !run && i+1 < int(count) {
+					value, err = r.ReadByte()
+				}
+			}
+			if err != nil {
+				return err
+			}
+
+			x += int(count)
+		}
+	}
+
+	return nil
+}
+
+// readOldScanline handles flat pixels and the original encoding, where a
+// 1, 1, 1, n pixel repeats the previous one n times, shifted by 8 bits for
+// each consecutive repeat pixel.
+func readOldScanline(r *bufio.Reader, scanline []byte) error {
+	shift := 0
+
+	for x := 0; x < len(scanline)/4; {
+		var pixel [4]byte
+		if _, err := io.ReadFull(r, pixel[:]); err != nil {
+			return err
+		}
+
+		if pixel[0] != 1 || pixel[1] != 1 || pixel[2] != 1 {
+			copy(scanline[x*4:], pixel[:])
+			x++
+			shift = 0
+			continue
+		}
+
+		if x == 0 {
+			return fmt.Errorf("repeat before the first pixel")
+		}
+
+		count := int(pixel[3]) << shift
+		if x+count > len(scanline)/4 {
+			return fmt.Errorf("repeat runs past the end of the scanline")
+		}
+
+		for i := 0; i < count; i++ {
+			copy(scanline[x*4:x*4+4], scanline[(x-1)*4:x*4])
+			x++
+		}
+		shift += 8
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package hdr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"testing"
+)
+
+// file builds an HDR file of width x height with the given resolution axis,
+// "-Y" or "+Y", followed by pixels as is.
+func file(width, height int, axis string, pixels []byte) []byte {
+	header := fmt.Sprintf("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n%s %d +X %d\n", axis, height, width)
+	return append([]byte(header), pixels...)
+}
+
+func TestDecode(t *testing.T) {
+	// Exponent 129 scales the mantissas by 1/128, 130 by 1/64, 0 is black
+	pixels := []byte{
+		64, 128, 192, 129, 0, 0, 0, 0,
+		2, 4, 6, 130, 255, 0, 0, 129,
+	}
+
+	tests := []struct {
+		axis string
+		want []float32
+	}{
+		{"-Y", []float32{0.50390625, 1.00390625, 1.50390625, 0, 0, 0, 0.0390625, 0.0703125, 0.1015625, 1.99609375, 0.00390625, 0.00390625}},
+		{"+Y", []float32{0.0390625, 0.0703125, 0.1015625, 1.99609375, 0.00390625, 0.00390625, 0.50390625, 1.00390625, 1.50390625, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.axis, func(t *testing.T) {
+			img, err := Decode(bytes.NewReader(file(2, 2, test.axis, pixels)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hdr := img.(*Image)
+			if hdr.Rect != image.Rect(0, 0, 2, 2) || hdr.Stride != 6 {
+				t.Fatalf("got %v, stride %d", hdr.Rect, hdr.Stride)
+			}
+
+			for i, want := range test.want {
+				if hdr.Pix[i] != want {
+					t.Fatalf("Pix %v, want %v", hdr.Pix, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeRLE(t *testing.T) {
+	// 8 pixels, each channel a run of 8 except blue, 8 literal values
+	pixels := []byte{2, 2, 0, 8, 128 + 8, 128, 128 + 8, 64, 8, 0, 16, 32, 48, 64, 80, 96, 112, 128 + 8, 129}
+
+	img, err := Decode(bytes.NewReader(file(8, 1, "-Y", pixels)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for x := 0; x < 8; x++ {
+		r, g, b := img.(*Image).RGB(x, 0)
+		if want := (float32(x*16) + 0.5) / 128; r != 128.5/128 || g != 64.5/128 || b != want {
+			t.Errorf("pixel %d is %v, %v, %v, want blue %v", x, r, g, b, want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no signature", []byte("RADIANCE\n\n-Y 1 +X 1\n\x00\x00\x00\x00")},
+		{"format", []byte("#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n\x00\x00\x00\x00")},
+		{"orientation", file(1, 1, "+X", []byte{0, 0, 0, 0})},
+		{"empty", file(0, 1, "-Y", nil)},
+		{"too many pixels", file(20000, 20000, "-Y", nil)},
+		{"too wide", file(1<<20, 1, "-Y", nil)},
+		{"overflowing size", []byte("#?RADIANCE\n\n-Y 3037000500 +X 3037000500\n")},
+		{"truncated", file(2, 2, "-Y", make([]byte, 12))},
+		{"run past the scanline", file(8, 1, "-Y", []byte{2, 2, 0, 8, 128 + 9, 0})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Decode(bytes.NewReader(test.data)); err == nil {
+				t.Errorf("Decode succeeded")
+			}
+		})
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(file(2, 2, "-Y", []byte{64, 128, 192, 129, 0, 0, 0, 0, 2, 4, 6, 130, 255, 0, 0, 129}))
+	f.Add(file(8, 1, "+Y", []byte{2, 2, 0, 8, 128 + 8, 128, 128 + 8, 64, 8, 0, 16, 32, 48, 64, 80, 96, 112, 128 + 8, 129}))
+	f.Add(file(3, 1, "-Y", []byte{1, 2, 3, 130, 1, 1, 1, 2}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		config, err := DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		img, err := Decode(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		hdr := img.(*Image)
+		if hdr.Rect != image.Rect(0, 0, config.Width, config.Height) {
+			t.Fatalf("decoded %v, config %dx%d", hdr.Rect, config.Width, config.Height)
+		}
+
+		if len(hdr.Pix) != config.Width*config.Height*3 {
+			t.Fatalf("%d floats for %dx%d pixels", len(hdr.Pix), config.Width, config.Height)
+		}
+	})
+}
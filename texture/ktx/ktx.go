@@ -0,0 +1,204 @@
+// Package ktx parses Khronos KTX 1 and KTX 2 files holding block compressed
+// textures, with mipmap chains, cube maps and arrays. Uncompressed formats,
+// 3D textures and KTX 2 supercompression (Basis, zstd) aren't supported.
+package ktx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/go-gl/example/texture/compressed"
+)
+
+var (
+	identifier1 = []byte{0xab, 'K', 'T', 'X', ' ', '1', '1', 0xbb, '\r', '\n', 0x1a, '\n'}
+	identifier2 = []byte{0xab, 'K', 'T', 'X', ' ', '2', '0', 0xbb, '\r', '\n', 0x1a, '\n'}
+)
+
+// vkFormats maps the VkFormat values of KTX 2 to GL internal formats.
+var vkFormats = map[uint32]uint32{
+	131: compressed.BC1RGB, 132: compressed.BC1SRGB,
+	133: compressed.BC1RGBA, 134: compressed.BC1SRGBAlpha,
+	135: compressed.BC2, 136: compressed.BC2SRGB,
+	137: compressed.BC3, 138: compressed.BC3SRGB,
+	139: compressed.BC4, 140: compressed.BC4Signed,
+	141: compressed.BC5, 142: compressed.BC5Signed,
+	143: compressed.BC6H, 144: compressed.BC6HSigned,
+	145: compressed.BC7, 146: compressed.BC7SRGB,
+	147: compressed.ETC2RGB, 148: compressed.ETC2SRGB,
+	149: compressed.ETC2RGBA1, 150: compressed.ETC2SRGBAlpha1,
+	151: compressed.ETC2RGBA, 152: compressed.ETC2SRGBAlpha,
+	153: compressed.EACR11, 154: compressed.EACR11Signed,
+	155: compressed.EACRG11, 156: compressed.EACRG11Signed,
+	157: compressed.ASTC4x4, 158: compressed.ASTC4x4SRGB,
+}
+
+// Is reports whether data starts like a KTX 1 or KTX 2 file.
+func Is(data []byte) bool {
+	return bytes.HasPrefix(data, identifier1) || bytes.HasPrefix(data, identifier2)
+}
+
+// Parse reads a KTX 1 or KTX 2 file. The returned images slice data.
+func Parse(data []byte) (*compressed.Image, error) {
+	switch {
+	case bytes.HasPrefix(data, identifier1):
+		return parse1(data)
+	case bytes.HasPrefix(data, identifier2):
+		return parse2(data)
+	}
+
+	return nil, fmt.Errorf("ktx: not a KTX file")
+}
+
+func newImage(glFormat uint32, width, height, depth, layers, faces, levels int) (*compressed.Image, error) {
+	format, ok := compressed.Lookup(glFormat)
+	if !ok {
+		return nil, fmt.Errorf("unsupported format 0x%x", glFormat)
+	}
+
+	if depth > 1 {
+		return nil, fmt.Errorf("3D textures aren't supported")
+	}
+
+	// 0 means not an array, which loads the same as an array of 1
+	layers = max(layers, 1)
+
+	levels, err := compressed.CheckSize(width, height, layers, faces, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &compressed.Image{
+		Format: format,
+		Width:  width,
+		Height: height,
+		Layers: layers,
+		Faces:  faces,
+		Levels: make([]compressed.Level, levels),
+	}
+
+	for level := range image.Levels {
+		image.Levels[level].Width, image.Levels[level].Height = compressed.LevelSize(width, height, level)
+	}
+
+	return image, nil
+}
+
+// parse1 reads KTX 1, whose header fields may be in either byte order.
+func parse1(data []byte) (*compressed.Image, error) {
+	const headerSize = 64
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("ktx: header truncated")
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	switch binary.LittleEndian.Uint32(data[12:]) {
+	case 0x04030201:
+	case 0x01020304:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("ktx: invalid endianness marker")
+	}
+
+	field := func(index int) int { return int(order.Uint32(data[12+index*4:])) }
+	glType, glInternalFormat := field(1), field(4)
+	width, height, depth := field(6), field(7), field(8)
+	layers, faces, levels := field(9), field(10), field(11)
+	keyValueBytes := field(12)
+
+	if glType != 0 {
+		return nil, fmt.Errorf("ktx: uncompressed formats aren't supported")
+	}
+
+	image, err := newImage(uint32(glInternalFormat), width, height, depth, layers, faces, levels)
+	if err != nil {
+		return nil, fmt.Errorf("ktx: %v", err)
+	}
+
+	if keyValueBytes > len(data)-headerSize {
+		return nil, fmt.Errorf("ktx: key/value data truncated")
+	}
+	offset := headerSize + keyValueBytes
+
+	for level := range image.Levels {
+		if len(data)-offset < 4 {
+			return nil, fmt.Errorf("ktx: level %d truncated", level)
+		}
+		offset += 4
+
+		// imageSize isn't trusted, blocks are what GL will read
+		size := image.Format.Size(image.Levels[level].Width, image.Levels[level].Height)
+
+		for i := 0; i < image.Layers*image.Faces; i++ {
+			if size > len(data)-offset {
+				return nil, fmt.Errorf("ktx: data truncated in level %d image %d", level, i)
+			}
+
+			image.Levels[level].Images = append(image.Levels[level].Images, data[offset:offset+size])
+			// Faces and levels are padded to 4 bytes, which block sizes
+			// already are
+			offset += size
+		}
+	}
+
+	return image, nil
+}
+
+// parse2 reads KTX 2, levels are located through the level index.
+func parse2(data []byte) (*compressed.Image, error) {
+	const headerSize = 80
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("ktx: header truncated")
+	}
+
+	field := func(index int) int { return int(binary.LittleEndian.Uint32(data[12+index*4:])) }
+	vkFormat := uint32(field(0))
+	width, height, depth := field(2), field(3), field(4)
+	layers, faces, levels := field(5), field(6), field(7)
+	supercompression := field(8)
+
+	if supercompression != 0 {
+		return nil, fmt.Errorf("ktx: supercompression scheme %d isn't supported", supercompression)
+	}
+
+	if vkFormat == 0 {
+		return nil, fmt.Errorf("ktx: undefined format, Basis Universal data needs transcoding")
+	}
+
+	glFormat, ok := vkFormats[vkFormat]
+	if !ok {
+		return nil, fmt.Errorf("ktx: unsupported VkFormat %d", vkFormat)
+	}
+
+	image, err := newImage(glFormat, width, height, depth, layers, faces, levels)
+	if err != nil {
+		return nil, fmt.Errorf("ktx: %v", err)
+	}
+
+	if len(data) < headerSize+len(image.Levels)*24 {
+		return nil, fmt.Errorf("ktx: level index truncated")
+	}
+
+	for level := range image.Levels {
+		entry := data[headerSize+level*24:]
+		offset, length := binary.LittleEndian.Uint64(entry), binary.LittleEndian.Uint64(entry[8:])
+
+		size := image.Format.Size(image.Levels[level].Width, image.Levels[level].Height)
+		count := image.Layers * image.Faces
+
+		if length != uint64(size*count) {
+			return nil, fmt.Errorf("ktx: level %d has %d bytes, expected %d", level, length, size*count)
+		}
+		if offset > uint64(len(data)) || length > uint64(len(data))-offset {
+			return nil, fmt.Errorf("ktx: level %d out of range of the file", level)
+		}
+
+		for i := 0; i < count; i++ {
+			start := int(offset) + i*size
+			image.Levels[level].Images = append(image.Levels[level].Images, data[start:start+size])
+		}
+	}
+
+	return image, nil
+}
@@ -0,0 +1,105 @@
+package ktx
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-gl/example/texture/compressed"
+)
+
+// file1 builds a little endian KTX 1 file whose levels hold size bytes per
+// image.
+func file1(glFormat uint32, width, height, layers, faces int, sizes []int) []byte {
+	data := append([]byte(nil), identifier1...)
+	for _, value := range []uint32{0x04030201, 0, 1, 0, glFormat, 0, uint32(width), uint32(height), 0, uint32(layers), uint32(faces), uint32(len(sizes)), 0} {
+		data = binary.LittleEndian.AppendUint32(data, value)
+	}
+
+	for _, size := range sizes {
+		data = binary.LittleEndian.AppendUint32(data, uint32(size))
+		data = append(data, make([]byte, size*max(layers, 1)*faces)...)
+	}
+
+	return data
+}
+
+// file2 builds a KTX 2 file with its levels laid out after the level index.
+func file2(vkFormat uint32, width, height, layers, faces int, sizes []int) []byte {
+	data := append([]byte(nil), identifier2...)
+	for _, value := range []uint32{vkFormat, 1, uint32(width), uint32(height), 0, uint32(layers), uint32(faces), uint32(len(sizes)), 0} {
+		data = binary.LittleEndian.AppendUint32(data, value)
+	}
+	// Data format and key/value descriptors, supercompression global data
+	data = append(data, make([]byte, 80-len(data))...)
+
+	offset := len(data) + 24*len(sizes)
+	var levels []byte
+	for _, size := range sizes {
+		length := size * max(layers, 1) * faces
+		levels = binary.LittleEndian.AppendUint64(levels, uint64(offset))
+		levels = binary.LittleEndian.AppendUint64(levels, uint64(length))
+		levels = binary.LittleEndian.AppendUint64(levels, uint64(length))
+		offset += length
+	}
+	data = append(data, levels...)
+
+	return append(data, make([]byte, offset-len(data))...)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		levels int
+		layers int
+		faces  int
+	}{
+		{"ktx 1", file1(compressed.BC1RGBA, 8, 8, 0, 1, []int{32, 8, 8, 8}), 4, 1, 1},
+		{"ktx 1 cube", file1(compressed.BC3, 4, 4, 0, 6, []int{16}), 1, 1, 6},
+		{"ktx 2 array", file2(145, 8, 4, 3, 1, []int{32, 16}), 2, 3, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			image, err := Parse(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(image.Levels) != test.levels || image.Layers != test.layers || image.Faces != test.faces {
+				t.Errorf("%d levels, %d layers, %d faces", len(image.Levels), image.Layers, image.Faces)
+			}
+
+			if _, err := Parse(test.data[:len(test.data)-1]); err == nil {
+				t.Errorf("truncated file parsed")
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(file1(compressed.BC1RGBA, 4, 4, 0, 1, []int{8}))
+	f.Add(file1(compressed.BC1RGBA, 8, 8, 0, 1, []int{32, 8, 8, 8}))
+	f.Add(file1(compressed.ETC2RGBA, 4, 4, 2, 6, []int{16}))
+	f.Add(file2(145, 8, 4, 3, 1, []int{32, 16}))
+	f.Add(file2(157, 4, 4, 0, 6, []int{16}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		image, err := Parse(data)
+		if err != nil {
+			return
+		}
+
+		for level, mip := range image.Levels {
+			if len(mip.Images) != image.Layers*image.Faces {
+				t.Fatalf("level %d has %d images, want %d", level, len(mip.Images), image.Layers*image.Faces)
+			}
+
+			for _, data := range mip.Images {
+				if len(data) != image.Format.Size(mip.Width, mip.Height) {
+					t.Fatalf("level %d image of %d bytes, want %d", level, len(data), image.Format.Size(mip.Width, mip.Height))
+				}
+			}
+		}
+	})
+}
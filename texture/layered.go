@@ -0,0 +1,261 @@
+package texture
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/texture/hdr"
+	"github.com/go-gl/gl/v2.1/gl"
+	xdraw "golang.org/x/image/draw"
+)
+
+// LoadArray loads images of the same size to the layers of a new 2D array
+// texture, see ArrayFromImages.
+func LoadArray(paths []string, options Options) (*Texture, error) {
+	images := make([]image.Image, len(paths))
+
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		images[i], err = decode(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("texture: %s: %v", path, err)
+		}
+	}
+
+	texture, err := ArrayFromImages(images, options)
+	if err != nil {
+		return nil, fmt.Errorf("texture: %v", err)
+	}
+
+	return texture, nil
+}
+
+// ArrayFromImages uploads images to the layers of a new 2D array texture,
+// each converted as FromImage does. Images of different sizes are an error
+// unless Options.Resize is set.
+func ArrayFromImages(images []image.Image, options Options) (*Texture, error) {
+	return newLayered(gl.TEXTURE_2D_ARRAY, images, options)
+}
+
+// VolumeFromImages uploads images to the slices of a new 3D texture, the
+// first image at W = 0. Sizes have to match as for ArrayFromImages.
+func VolumeFromImages(slices []image.Image, options Options) (*Texture, error) {
+	return newLayered(gl.TEXTURE_3D, slices, options)
+}
+
+func newLayered(target uint32, images []image.Image, options Options) (*Texture, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images")
+	}
+
+	size := images[0].Bounds().Size()
+
+	var maximum int32
+	if target == gl.TEXTURE_3D {
+		gl.GetIntegerv(gl.MAX_3D_TEXTURE_SIZE, &maximum)
+	} else {
+		gl.GetIntegerv(gl.MAX_ARRAY_TEXTURE_LAYERS_EXT, &maximum)
+	}
+	if len(images) > int(maximum) {
+		return nil, fmt.Errorf("%d layers, the driver supports %d", len(images), maximum)
+	}
+
+	texture := &Texture{
+		Target:  target,
+		Width:   size.X,
+		Height:  size.Y,
+		Depth:   len(images),
+		options: options,
+	}
+
+	// Check and resize everything before creating the texture
+	for i, img := range images {
+		var err error
+		if images[i], err = texture.fit(img); err != nil {
+			return nil, fmt.Errorf("layer %d: %v", i, err)
+		}
+	}
+
+	gl.GenTextures(1, &texture.Id)
+	restore := texture.edit()
+
+	for i, img := range images {
+		data := prepare(img, options)
+
+		if i == 0 {
+			texture.Format = orDefault(options.InternalFormat, data.internalFormat)
+			gl.TexImage3D(texture.Target, 0, texture.Format, int32(texture.Width), int32(texture.Height), int32(texture.Depth), 0, data.format, data.dataType, nil)
+		}
+
+		texture.uploadLayer(i, data)
+	}
+
+	texture.SetOptions(options)
+
+	restore()
+	gldebug.Check("texture.newLayered")
+
+	return texture, nil
+}
+
+// SetLayer replaces one layer of an array texture or one slice of a 3D
+// texture, converting img with the options the texture was created with.
+// Mipmaps are regenerated when the texture has them.
+func (texture *Texture) SetLayer(layer int, img image.Image) error {
+	if texture.Target != gl.TEXTURE_2D_ARRAY && texture.Target != gl.TEXTURE_3D {
+		return fmt.Errorf("texture: layers need an array or 3D texture")
+	}
+
+	if layer < 0 || layer >= texture.Depth {
+		return fmt.Errorf("texture: layer %d out of range of %d", layer, texture.Depth)
+	}
+
+	img, err := texture.fit(img)
+	if err != nil {
+		return fmt.Errorf("texture: layer %d: %v", layer, err)
+	}
+
+	restore := texture.edit()
+	texture.uploadLayer(layer, prepare(img, texture.options))
+
+	if texture.options.Mipmaps {
+		gl.GenerateMipmap(texture.Target)
+	}
+
+	restore()
+	gldebug.Check("texture.SetLayer")
+
+	return nil
+}
+
+// uploadLayer uploads to level 0 of the bound texture.
+func (texture *Texture) uploadLayer(layer int, data pixelData) {
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.TexSubImage3D(texture.Target, 0, 0, 0, int32(layer), int32(data.width), int32(data.height), 1, data.format, data.dataType, gl.Ptr(data.pixels))
+}
+
+// fit returns img if it has the texture's size, or a scaled copy of it with
+// Options.Resize.
+func (texture *Texture) fit(img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() == texture.Width && bounds.Dy() == texture.Height {
+		return img, nil
+	}
+
+	if !texture.options.Resize {
+		return nil, fmt.Errorf("%dx%d doesn't match the texture's %dx%d", bounds.Dx(), bounds.Dy(), texture.Width, texture.Height)
+	}
+
+	rect := image.Rect(0, 0, texture.Width, texture.Height)
+
+	var resized xdraw.Image
+	switch img.(type) {
+	case *hdr.Image:
+		// Scaling goes through color.Color, which would clamp HDR values
+		return nil, fmt.Errorf("HDR images can't be resized")
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+		resized = image.NewNRGBA64(rect)
+	default:
+		resized = image.NewNRGBA(rect)
+	}
+
+	xdraw.CatmullRom.Scale(resized, rect, img, bounds, xdraw.Src, nil)
+
+	return resized, nil
+}
+
+// RawVolume describes a headerless volume file: Depth slices of Height rows
+// of Width texels one after the other, each texel having the components of
+// Format (gl.RED, gl.RG, gl.RGB or gl.RGBA) of type DataType
+// (gl.UNSIGNED_BYTE, gl.UNSIGNED_SHORT, gl.HALF_FLOAT or gl.FLOAT), in the
+// machine's byte order.
+type RawVolume struct {
+	Width    int
+	Height   int
+	Depth    int
+	Format   uint32
+	DataType uint32
+}
+
+// LoadVolume loads a raw volume file to a new 3D texture, see VolumeFromRaw.
+func LoadVolume(path string, volume RawVolume, options Options) (*Texture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	texture, err := VolumeFromRaw(data, volume, options)
+	if err != nil {
+		return nil, fmt.Errorf("texture: %s: %v", path, err)
+	}
+
+	return texture, nil
+}
+
+// VolumeFromRaw uploads texels laid out as volume says to a new 3D texture.
+// They're uploaded as stored, the first row at V = 0: ColorSpace, FlipY and
+// Premultiply don't apply. InternalFormat defaults to the sized format
+// matching the data, like gl.R8 for gl.RED bytes or gl.RGBA32F for gl.RGBA
+// floats.
+func VolumeFromRaw(data []byte, volume RawVolume, options Options) (*Texture, error) {
+	components := map[uint32]int{gl.RED: 1, gl.RG: 2, gl.RGB: 3, gl.RGBA: 4}[volume.Format]
+	componentSize := map[uint32]int{gl.UNSIGNED_BYTE: 1, gl.UNSIGNED_SHORT: 2, gl.HALF_FLOAT: 2, gl.FLOAT: 4}[volume.DataType]
+
+	if components == 0 {
+		return nil, fmt.Errorf("unsupported format 0x%x", volume.Format)
+	}
+	if componentSize == 0 {
+		return nil, fmt.Errorf("unsupported data type 0x%x", volume.DataType)
+	}
+
+	var maximum int32
+	gl.GetIntegerv(gl.MAX_3D_TEXTURE_SIZE, &maximum)
+	if volume.Width < 1 || volume.Height < 1 || volume.Depth < 1 || max(volume.Width, volume.Height, volume.Depth) > int(maximum) {
+		return nil, fmt.Errorf("invalid size %dx%dx%d, the driver supports up to %d", volume.Width, volume.Height, volume.Depth, maximum)
+	}
+
+	if expected := volume.Width * volume.Height * volume.Depth * components * componentSize; len(data) != expected {
+		return nil, fmt.Errorf("%d bytes, %dx%dx%d texels need %d", len(data), volume.Width, volume.Height, volume.Depth, expected)
+	}
+
+	texture := &Texture{
+		Target:  gl.TEXTURE_3D,
+		Width:   volume.Width,
+		Height:  volume.Height,
+		Depth:   volume.Depth,
+		Format:  orDefault(options.InternalFormat, rawInternalFormat(components, volume.DataType)),
+		options: options,
+	}
+
+	gl.GenTextures(1, &texture.Id)
+	restore := texture.edit()
+
+	// Rows of odd sized texels aren't 4-byte aligned
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage3D(texture.Target, 0, texture.Format, int32(volume.Width), int32(volume.Height), int32(volume.Depth), 0, volume.Format, volume.DataType, gl.Ptr(data))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+
+	texture.SetOptions(options)
+
+	restore()
+	gldebug.Check("texture.VolumeFromRaw")
+
+	return texture, nil
+}
+
+func rawInternalFormat(components int, dataType uint32) int32 {
+	formats := map[uint32][4]int32{
+		gl.UNSIGNED_BYTE:  {gl.R8, gl.RG8, gl.RGB8, gl.RGBA8},
+		gl.UNSIGNED_SHORT: {gl.R16, gl.RG16, gl.RGB16, gl.RGBA16},
+		gl.HALF_FLOAT:     {gl.R16F, gl.RG16F, gl.RGB16F_ARB, gl.RGBA16F_ARB},
+		gl.FLOAT:          {gl.R32F, gl.RG32F, gl.RGB32F, gl.RGBA32F_ARB},
+	}
+
+	return formats[dataType][components-1]
+}
@@ -0,0 +1,361 @@
+// Package texture loads images into GL textures.
+package texture
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/texture/compressed"
+	"github.com/go-gl/example/texture/dds"
+	_ "github.com/go-gl/example/texture/hdr"
+	"github.com/go-gl/example/texture/ktx"
+	"github.com/go-gl/example/texture/tga"
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	_ "golang.org/x/image/bmp"
+)
+
+// ColorSpace tells how texel values are to be read.
+type ColorSpace int
+
+const (
+	// Linear textures are sampled as stored, for data like normal maps,
+	// roughness or masks.
+	Linear ColorSpace = iota
+	// SRGB textures are decoded to linear when sampled, for colors authored
+	// on screen like albedo and UI images.
+	SRGB
+)
+
+// Options control how a texture is sampled and stored. The zero value is a
+// linearly filtered RGBA8 texture clamped to its edges without mipmaps,
+// uploaded as stored with the first row of the image at V = 0.
+type Options struct {
+	// MinFilter defaults to gl.LINEAR_MIPMAP_LINEAR with mipmaps and
+	// gl.LINEAR without.
+	MinFilter int32
+	// MagFilter defaults to gl.LINEAR.
+	MagFilter int32
+
+	// Wrap modes per axis, gl.CLAMP_TO_EDGE by default. R only matters to
+	// cube maps and 3D textures.
+	WrapS int32
+	WrapT int32
+	WrapR int32
+	// BorderColor is sampled outside the texture with gl.CLAMP_TO_BORDER.
+	BorderColor [4]float32
+
+	// Anisotropy is the maximum anisotropic filtering, clamped to what the
+	// driver supports. 0 or 1 turn it off, it's ignored by drivers without
+	// anisotropic filtering.
+	Anisotropy float32
+
+	Mipmaps bool
+
+	// InternalFormat defaults to gl.SRGB8_ALPHA8 for SRGB textures and
+	// gl.RGBA8 otherwise.
+	InternalFormat int32
+
+	ColorSpace ColorSpace
+	// FlipY puts the last row of the image at V = 0, so images appear upright
+	// with UVs going up from the bottom as in GL.
+	FlipY bool
+	// Premultiply multiplies colors by alpha, in linear space for SRGB
+	// textures. Images are otherwise uploaded with straight alpha.
+	Premultiply bool
+
+	// Resize scales the layers of array textures and the slices of 3D
+	// textures to the size of the first one, instead of failing when they
+	// differ.
+	Resize bool
+}
+
+type Texture struct {
+	Id     uint32
+	Target uint32
+	Width  int
+	Height int
+	// Depth is the number of layers of array textures or slices of 3D
+	// textures, 1 otherwise.
+	Depth int
+	// Format is the internal format the texture is stored in.
+	Format int32
+
+	// options converts the images given to SetLayer.
+	options Options
+	// levels is the number of mipmap levels, for Size.
+	levels int
+}
+
+// Load decodes an image file and uploads it to a new texture. PNG, JPEG,
+// BMP, Radiance HDR, DDS and KTX files are recognized by their contents, TGA
+// files by their extension. DDS and KTX files are uploaded as they are, see
+// FromCompressed.
+func Load(path string, options Options) (*Texture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromData(path, data, options)
+}
+
+// LoadFS is Load reading the file from fsys.
+func LoadFS(fsys fs.FS, name string, options Options) (*Texture, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromData(name, data, options)
+}
+
+func fromData(name string, data []byte, options Options) (*Texture, error) {
+	prepared, err := Prepare(name, data, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return prepared.Upload()
+}
+
+// Prepared is an image file decoded and converted, ready to be uploaded.
+type Prepared struct {
+	name   string
+	upload func() (*Texture, error)
+}
+
+// Prepare does the work of Load that doesn't need GL on the contents of file
+// name, so it can run on any goroutine. Upload then has to be called on the
+// main thread.
+func Prepare(name string, data []byte, options Options) (*Prepared, error) {
+	if dds.Is(data) || ktx.Is(data) {
+		image, err := parseCompressed(data)
+		if err != nil {
+			return nil, fmt.Errorf("texture: %s: %v", name, err)
+		}
+
+		return &Prepared{name: name, upload: func() (*Texture, error) { return FromCompressed(image, options) }}, nil
+	}
+
+	img, err := decode(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("texture: %s: %v", name, err)
+	}
+
+	pixels := prepare(img, options)
+	return &Prepared{name: name, upload: func() (*Texture, error) { return fromPixels(pixels, options), nil }}, nil
+}
+
+// Upload creates the texture.
+func (prepared *Prepared) Upload() (*Texture, error) {
+	texture, err := prepared.upload()
+	if err != nil {
+		return nil, fmt.Errorf("texture: %s: %v", prepared.name, err)
+	}
+
+	return texture, nil
+}
+
+// decode decodes the image file path read into data, TGA files have no magic
+// number and are told apart by their extension.
+func decode(path string, data []byte) (image.Image, error) {
+	if strings.EqualFold(filepath.Ext(path), ".tga") {
+		return tga.Decode(bytes.NewReader(data))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// FromImage uploads img to a new 2D texture. 8-bit images are stored as
+// RGBA8, 16-bit ones (*image.RGBA64, *image.NRGBA64 and *image.Gray16) as
+// RGBA16 and *hdr.Image as RGB32F unless Options.InternalFormat says
+// otherwise, gl.RGBA16F halves the size of HDR textures. The SRGB color space
+// only applies to 8-bit images and premultiplying is done in linear space
+// for the others. img is left untouched, conversions work on a copy.
+func FromImage(img image.Image, options Options) *Texture {
+	return fromPixels(prepare(img, options), options)
+}
+
+// fromPixels is the GL half of FromImage, the async loader prepares images
+// on other goroutines.
+func fromPixels(data pixelData, options Options) *Texture {
+	format := options.InternalFormat
+	if format == 0 {
+		format = data.internalFormat
+	}
+
+	texture := &Texture{
+		Target: gl.TEXTURE_2D,
+		Width:  data.width,
+		Height: data.height,
+		Depth:  1,
+		Format: format,
+	}
+
+	gl.GenTextures(1, &texture.Id)
+	restore := texture.edit()
+
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.TexImage2D(texture.Target, 0, format, int32(texture.Width), int32(texture.Height), 0, data.format, data.dataType, gl.Ptr(data.pixels))
+
+	texture.SetOptions(options)
+
+	restore()
+	gldebug.Check("texture.fromPixels")
+
+	return texture
+}
+
+// SetOptions changes the texture's sampling. InternalFormat is ignored, the
+// storage is already allocated, and mipmaps are regenerated from level 0.
+func (texture *Texture) SetOptions(options Options) {
+	restore := texture.edit()
+
+	minFilter := options.MinFilter
+	if minFilter == 0 {
+		minFilter = gl.LINEAR
+		if options.Mipmaps {
+			minFilter = gl.LINEAR_MIPMAP_LINEAR
+		}
+	}
+
+	gl.TexParameteri(texture.Target, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(texture.Target, gl.TEXTURE_MAG_FILTER, orDefault(options.MagFilter, gl.LINEAR))
+	gl.TexParameteri(texture.Target, gl.TEXTURE_WRAP_S, orDefault(options.WrapS, gl.CLAMP_TO_EDGE))
+	gl.TexParameteri(texture.Target, gl.TEXTURE_WRAP_T, orDefault(options.WrapT, gl.CLAMP_TO_EDGE))
+	gl.TexParameteri(texture.Target, gl.TEXTURE_WRAP_R, orDefault(options.WrapR, gl.CLAMP_TO_EDGE))
+	gl.TexParameterfv(texture.Target, gl.TEXTURE_BORDER_COLOR, &options.BorderColor[0])
+
+	if options.Anisotropy > 1 && anisotropySupported() {
+		var maximum float32
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &maximum)
+		gl.TexParameterf(texture.Target, gl.TEXTURE_MAX_ANISOTROPY, min(options.Anisotropy, maximum))
+	}
+
+	if options.Mipmaps {
+		gl.GenerateMipmap(texture.Target)
+
+		texture.levels = 1
+		for size := max(texture.Width, texture.Height, texture.depth()); size > 1; size /= 2 {
+			texture.levels++
+		}
+	}
+
+	restore()
+	gldebug.Check("texture.SetOptions")
+}
+
+func anisotropySupported() bool {
+	return glfw.ExtensionSupported("GL_EXT_texture_filter_anisotropic") || glfw.ExtensionSupported("GL_ARB_texture_filter_anisotropic")
+}
+
+func orDefault(value int32, fallback int32) int32 {
+	if value == 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// depth is the size along the third axis, which only 3D textures have.
+func (texture *Texture) depth() int {
+	if texture.Target == gl.TEXTURE_3D {
+		return texture.Depth
+	}
+
+	return 1
+}
+
+// Size estimates the memory the texture takes on the GPU in bytes, assuming
+// RGB formats are padded to RGBA as drivers usually do.
+func (texture *Texture) Size() int {
+	images := texture.Depth
+	if texture.Target == gl.TEXTURE_CUBE_MAP {
+		images = 6
+	}
+
+	format, isCompressed := compressed.Lookup(uint32(texture.Format))
+
+	total := 0
+	for level := 0; level < max(texture.levels, 1); level++ {
+		width, height := compressed.LevelSize(texture.Width, texture.Height, level)
+		if texture.Target == gl.TEXTURE_3D {
+			images = max(texture.Depth>>level, 1)
+		}
+
+		if isCompressed {
+			total += format.Size(width, height) * images
+		} else {
+			total += width * height * texelSize(texture.Format) * images
+		}
+	}
+
+	return total
+}
+
+func texelSize(format int32) int {
+	switch format {
+	case gl.R8:
+		return 1
+	case gl.RG8, gl.R16, gl.R16F:
+		return 2
+	case gl.RGB16, gl.RGB16F_ARB, gl.RGBA16, gl.RGBA16F_ARB, gl.RG32F:
+		return 8
+	case gl.RGB32F, gl.RGBA32F_ARB:
+		return 16
+	}
+
+	// RGBA8 and sRGB, RGB8, RG16 and R32F, or unknown
+	return 4
+}
+
+// Bind binds the texture to texture unit unit, the value to give the
+// sampler uniform.
+func (texture *Texture) Bind(unit int) {
+	gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+	gl.BindTexture(texture.Target, texture.Id)
+}
+
+// edit binds the texture to the active unit to change it and returns a
+// function binding back the texture that was there, so creating or changing
+// textures doesn't undo the bindings materials keep track of.
+func (texture *Texture) edit() (restore func()) {
+	var previous int32
+	gl.GetIntegerv(bindingQuery(texture.Target), &previous)
+	gl.BindTexture(texture.Target, texture.Id)
+
+	return func() { gl.BindTexture(texture.Target, uint32(previous)) }
+}
+
+func bindingQuery(target uint32) uint32 {
+	switch target {
+	case gl.TEXTURE_CUBE_MAP:
+		return gl.TEXTURE_BINDING_CUBE_MAP
+	case gl.TEXTURE_2D_ARRAY:
+		return gl.TEXTURE_BINDING_2D_ARRAY
+	case gl.TEXTURE_3D:
+		return gl.TEXTURE_BINDING_3D
+	}
+
+	return gl.TEXTURE_BINDING_2D
+}
+
+// Label names the texture in GL debug output.
+func (texture *Texture) Label(name string) {
+	gldebug.Label(gl.TEXTURE, texture.Id, name)
+}
+
+func (texture *Texture) Delete() {
+	gl.DeleteTextures(1, &texture.Id)
+	texture.Id = 0
+}
@@ -0,0 +1,268 @@
+// Package tga decodes Truevision TGA images.
+//
+// Color mapped, true color and grayscale images are supported, raw or run
+// length encoded, at 8, 15, 16, 24 and 32 bits per pixel. TGA has no magic
+// number, so the format isn't registered with the image package and callers
+// pick it by file extension.
+package tga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"slices"
+)
+
+const (
+	typeColorMapped    = 1
+	typeTrueColor      = 2
+	typeGrayscale      = 3
+	typeRLEColorMapped = 9
+	typeRLETrueColor   = 10
+	typeRLEGrayscale   = 11
+)
+
+// maxPixels bounds the size read from the header, 16384x16384 being the
+// largest texture most GPUs take.
+const maxPixels = 16384 * 16384
+
+type header struct {
+	IDLength        uint8
+	ColorMapType    uint8
+	ImageType       uint8
+	ColorMapOrigin  uint16
+	ColorMapLength  uint16
+	ColorMapDepth   uint8
+	XOrigin         uint16
+	YOrigin         uint16
+	Width           uint16
+	Height          uint16
+	PixelDepth      uint8
+	ImageDescriptor uint8
+}
+
+func readHeader(r io.Reader) (header, error) {
+	var h header
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return header{}, fmt.Errorf("tga: header: %v", err)
+	}
+
+	switch h.ImageType {
+	case typeColorMapped, typeRLEColorMapped:
+		if h.ColorMapType != 1 || h.PixelDepth != 8 {
+			return header{}, fmt.Errorf("tga: color mapped image needs a color map and 8-bit indices")
+		}
+		if !validDepth(h.ColorMapDepth) || h.ColorMapDepth == 8 {
+			return header{}, fmt.Errorf("tga: unsupported color map depth %d", h.ColorMapDepth)
+		}
+	case typeTrueColor, typeRLETrueColor:
+		if !validDepth(h.PixelDepth) || h.PixelDepth == 8 {
+			return header{}, fmt.Errorf("tga: unsupported true color depth %d", h.PixelDepth)
+		}
+	case typeGrayscale, typeRLEGrayscale:
+		if h.PixelDepth != 8 && h.PixelDepth != 16 {
+			return header{}, fmt.Errorf("tga: unsupported grayscale depth %d", h.PixelDepth)
+		}
+	default:
+		return header{}, fmt.Errorf("tga: unsupported image type %d", h.ImageType)
+	}
+
+	if h.Width == 0 || h.Height == 0 {
+		return header{}, fmt.Errorf("tga: empty image")
+	}
+
+	if int(h.Width)*int(h.Height) > maxPixels {
+		return header{}, fmt.Errorf("tga: image of %dx%d pixels is too large", h.Width, h.Height)
+	}
+
+	return h, nil
+}
+
+func validDepth(depth uint8) bool {
+	return depth == 8 || depth == 15 || depth == 16 || depth == 24 || depth == 32
+}
+
+// DecodeConfig returns the size of a TGA image without decoding it.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	return image.Config{ColorModel: color.NRGBAModel, Width: int(h.Width), Height: int(h.Height)}, nil
+}
+
+// Decode reads a TGA image as an *image.NRGBA, TGA alpha being straight.
+// Images without alpha bits are opaque.
+func Decode(r io.Reader) (image.Image, error) {
+	reader := bufio.NewReader(r)
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := reader.Discard(int(h.IDLength)); err != nil {
+		return nil, fmt.Errorf("tga: image ID: %v", err)
+	}
+
+	var palette [][4]byte
+	if h.ColorMapType == 1 {
+		entrySize := (int(h.ColorMapDepth) + 7) / 8
+		data := make([]byte, int(h.ColorMapLength)*entrySize)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, fmt.Errorf("tga: color map: %v", err)
+		}
+
+		// Other image types may carry a color map too, it goes unused
+		for i := 0; i < int(h.ColorMapLength); i++ {
+			palette = append(palette, decodePixel(data[i*entrySize:], h.ColorMapDepth, false))
+		}
+	}
+
+	width, height := int(h.Width), int(h.Height)
+	pixelSize := (int(h.PixelDepth) + 7) / 8
+
+	var raw []byte
+	if h.ImageType >= typeRLEColorMapped {
+		raw, err = readRLE(reader, width*height*pixelSize, pixelSize)
+	} else {
+		raw, err = readRaw(reader, width*height*pixelSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tga: pixels: %v", err)
+	}
+
+	colorMapped := h.ImageType == typeColorMapped || h.ImageType == typeRLEColorMapped
+	grayscale := h.ImageType == typeGrayscale || h.ImageType == typeRLEGrayscale
+	hasAlpha := h.ImageDescriptor&0x0f != 0
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rightToLeft := h.ImageDescriptor&0x10 != 0
+	topToBottom := h.ImageDescriptor&0x20 != 0
+
+	for y := 0; y < height; y++ {
+		row := y
+		if !topToBottom {
+			row = height - 1 - y
+		}
+
+		for x := 0; x < width; x++ {
+			column := x
+			if rightToLeft {
+				column = width - 1 - x
+			}
+
+			pixel := raw[(y*width+x)*pixelSize:]
+
+			var rgba [4]byte
+			switch {
+			case colorMapped:
+				index := int(pixel[0]) - int(h.ColorMapOrigin)
+				if index < 0 || index >= len(palette) {
+					return nil, fmt.Errorf("tga: color index %d out of range", pixel[0])
+				}
+				rgba = palette[index]
+			case grayscale:
+				rgba = [4]byte{pixel[0], pixel[0], pixel[0], 255}
+				if h.PixelDepth == 16 && hasAlpha {
+					rgba[3] = pixel[1]
+				}
+			default:
+				rgba = decodePixel(pixel, h.PixelDepth, hasAlpha)
+			}
+
+			copy(img.Pix[row*img.Stride+column*4:], rgba[:])
+		}
+	}
+
+	return img, nil
+}
+
+// decodePixel reads a BGR(A) pixel, 15 and 16 bit ones being 5 bits per
+// channel with an optional alpha bit.
+func decodePixel(data []byte, depth uint8, hasAlpha bool) [4]byte {
+	switch depth {
+	case 15, 16:
+		value := binary.LittleEndian.Uint16(data)
+		expand := func(bits uint16) byte { return byte(bits<<3 | bits>>2) }
+
+		alpha := byte(255)
+		if depth == 16 && hasAlpha && value&0x8000 == 0 {
+			alpha = 0
+		}
+
+		return [4]byte{expand(value >> 10 & 0x1f), expand(value >> 5 & 0x1f), expand(value & 0x1f), alpha}
+	case 24:
+		return [4]byte{data[2], data[1], data[0], 255}
+	case 32:
+		alpha := byte(255)
+		if hasAlpha {
+			alpha = data[3]
+		}
+		return [4]byte{data[2], data[1], data[0], alpha}
+	}
+
+	// 8-bit color map entries aren't allowed, readHeader rejects them
+	return [4]byte{data[0], data[0], data[0], 255}
+}
+
+// readRaw reads size bytes a chunk at a time, so a header claiming a huge
+// image only costs as much memory as the file actually holds.
+func readRaw(r *bufio.Reader, size int) ([]byte, error) {
+	const chunkSize = 1 << 20
+
+	var raw []byte
+	for len(raw) < size {
+		start := len(raw)
+		n := min(size-start, chunkSize)
+		raw = slices.Grow(raw, n)[:start+n]
+
+		if _, err := io.ReadFull(r, raw[start:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return raw, nil
+}
+
+// readRLE decodes run length encoded packets until size bytes of pixels are
+// read. Packets may cross rows but not the end of the image. The buffer grows
+// with the packets like readRaw's.
+func readRLE(r *bufio.Reader, size int, pixelSize int) ([]byte, error) {
+	var raw []byte
+
+	for len(raw) < size {
+		packet, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		count := int(packet&0x7f) + 1
+		if len(raw)+count*pixelSize > size {
+			return nil, fmt.Errorf("packet runs past the end of the image")
+		}
+
+		start := len(raw)
+		if packet&0x80 == 0 {
+			raw = slices.Grow(raw, count*pixelSize)[:start+count*pixelSize]
+			if _, err := io.ReadFull(r, raw[start:]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		raw = slices.Grow(raw, count*pixelSize)[:start+pixelSize]
+		if _, err := io.ReadFull(r, raw[start:]); err != nil {
+			return nil, err
+		}
+		for ; count > 1; count-- {
+			raw = append(raw, raw[start:start+pixelSize]...)
+		}
+	}
+
+	return raw, nil
+}
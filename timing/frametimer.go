@@ -0,0 +1,249 @@
+package timing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type Options struct {
+	// Number of frames the rolling statistics are computed over, 120 when
+	// left at zero.
+	Frames int
+
+	// GPU measures the time the GPU spends on the frame's commands with
+	// GL_TIME_ELAPSED queries. Needs a current GL context.
+	GPU bool
+
+	// Record keeps every sample for WriteCSV instead of only the last Frames.
+	Record bool
+
+	// Title appends the stats to the window title, Graph draws a frame time
+	// graph in the top left corner of the window.
+	Title bool
+	Graph bool
+}
+
+// Sample holds the timings of a single frame. GPU is zero until the query
+// result comes back, usually a couple of frames later.
+type Sample struct {
+	Frame int
+	// Time between the start of this frame and the next one.
+	Total time.Duration
+	// Time spent in the update callback.
+	CPU  time.Duration
+	Swap time.Duration
+	GPU  time.Duration
+}
+
+type FrameTimer struct {
+	Options Options
+
+	frame      int
+	frameStart time.Time
+	updateEnd  time.Time
+
+	total, cpu, swap, gpu *Series
+
+	samples []Sample
+	gpuTime *gpuTimer
+	graph   *graph
+}
+
+func NewFrameTimer(options Options) *FrameTimer {
+	if options.Frames == 0 {
+		options.Frames = 120
+	}
+
+	timer := &FrameTimer{
+		Options: options,
+		total:   NewSeries(options.Frames),
+		cpu:     NewSeries(options.Frames),
+		swap:    NewSeries(options.Frames),
+		gpu:     NewSeries(options.Frames),
+		frame:   -1,
+	}
+
+	if options.GPU {
+		timer.gpuTime = newGPUTimer()
+	}
+
+	return timer
+}
+
+// BeginFrame is called before the update callback runs.
+func (timer *FrameTimer) BeginFrame() {
+	now := time.Now()
+
+	if timer.frame >= 0 {
+		total := now.Sub(timer.frameStart)
+		timer.total.Add(total)
+		timer.sample(timer.frame).Total = total
+	}
+
+	timer.frame++
+	timer.frameStart = now
+	*timer.sample(timer.frame) = Sample{Frame: timer.frame}
+
+	if timer.gpuTime != nil {
+		timer.gpuTime.collect(timer.addGPU)
+		timer.gpuTime.begin(timer.frame, timer.addGPU)
+	}
+}
+
+// EndUpdate is called once the update callback returned, before swapping.
+func (timer *FrameTimer) EndUpdate() {
+	if timer.gpuTime != nil {
+		timer.gpuTime.end()
+	}
+
+	timer.updateEnd = time.Now()
+
+	cpu := timer.updateEnd.Sub(timer.frameStart)
+	timer.cpu.Add(cpu)
+	timer.sample(timer.frame).CPU = cpu
+}
+
+func (timer *FrameTimer) EndSwap() {
+	swap := time.Since(timer.updateEnd)
+	timer.swap.Add(swap)
+	timer.sample(timer.frame).Swap = swap
+}
+
+func (timer *FrameTimer) addGPU(frame int, elapsed time.Duration) {
+	timer.gpu.Add(elapsed)
+
+	if sample := timer.sample(frame); sample.Frame == frame {
+		sample.GPU = elapsed
+	}
+}
+
+// sample returns where the timings of frame are stored. Without recording only
+// the last Frames samples are kept, older frames share their slot.
+func (timer *FrameTimer) sample(frame int) *Sample {
+	index := frame
+	if !timer.Options.Record {
+		index = frame % timer.Options.Frames
+	}
+
+	for len(timer.samples) <= index {
+		timer.samples = append(timer.samples, Sample{Frame: -1})
+	}
+
+	return &timer.samples[index]
+}
+
+func (timer *FrameTimer) Frame() int {
+	return timer.frame
+}
+
+func (timer *FrameTimer) Total() Stats {
+	return timer.total.Stats()
+}
+
+func (timer *FrameTimer) CPU() Stats {
+	return timer.cpu.Stats()
+}
+
+func (timer *FrameTimer) Swap() Stats {
+	return timer.swap.Stats()
+}
+
+func (timer *FrameTimer) GPU() Stats {
+	return timer.gpu.Stats()
+}
+
+func (timer *FrameTimer) String() string {
+	total := timer.Total()
+
+	fps := 0.0
+	if total.Avg > 0 {
+		fps = float64(time.Second) / float64(total.Avg)
+	}
+
+	text := fmt.Sprintf("%.0f fps, frame %s (max %s, p99 %s), cpu %s, swap %s",
+		fps, ms(total.Avg), ms(total.Max), ms(total.P99), ms(timer.CPU().Avg), ms(timer.Swap().Avg))
+
+	if timer.gpuTime != nil {
+		text += ", gpu " + ms(timer.GPU().Avg)
+	}
+
+	return text
+}
+
+// Samples returns the finished frames recorded so far in frame order.
+func (timer *FrameTimer) Samples() []Sample {
+	samples := make([]Sample, 0, len(timer.samples))
+
+	for _, sample := range timer.samples {
+		if sample.Frame >= 0 && sample.Frame < timer.frame {
+			samples = append(samples, sample)
+		}
+	}
+
+	if !timer.Options.Record {
+		sortByFrame(samples)
+	}
+
+	return samples
+}
+
+// WriteCSV writes one row per sample with timings in milliseconds.
+func (timer *FrameTimer) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"frame", "total_ms", "cpu_ms", "swap_ms", "gpu_ms"}); err != nil {
+		return err
+	}
+
+	for _, sample := range timer.Samples() {
+		row := []string{
+			strconv.Itoa(sample.Frame),
+			msValue(sample.Total),
+			msValue(sample.CPU),
+			msValue(sample.Swap),
+			msValue(sample.GPU),
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Delete frees the GL objects used for GPU timing and the graph.
+func (timer *FrameTimer) Delete() {
+	if timer.gpuTime != nil {
+		timer.gpuTime.delete()
+		timer.gpuTime = nil
+	}
+
+	if timer.graph != nil {
+		timer.graph.delete()
+		timer.graph = nil
+	}
+}
+
+func sortByFrame(samples []Sample) {
+	// The ring is already ordered apart from one rotation point.
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Frame < samples[i-1].Frame {
+			rotated := append(append([]Sample(nil), samples[i:]...), samples[:i]...)
+			copy(samples, rotated)
+			return
+		}
+	}
+}
+
+func ms(duration time.Duration) string {
+	return msValue(duration) + "ms"
+}
+
+func msValue(duration time.Duration) string {
+	return strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', 2, 64)
+}
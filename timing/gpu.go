@@ -0,0 +1,74 @@
+package timing
+
+import (
+	"time"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Query results only become available a few frames after they're issued, so
+// a small ring of queries is cycled instead of waiting on the current one.
+const gpuQueryCount = 4
+
+type gpuTimer struct {
+	queries [gpuQueryCount]uint32
+	// Frame each query was issued for, -1 once its result has been read.
+	frames [gpuQueryCount]int
+	next   int
+}
+
+func newGPUTimer() *gpuTimer {
+	timer := &gpuTimer{}
+	gl.GenQueries(gpuQueryCount, &timer.queries[0])
+
+	for i := range timer.frames {
+		timer.frames[i] = -1
+	}
+
+	return timer
+}
+
+// begin starts timing the GL commands of frame. If the query it reuses hasn't
+// been read yet its result is waited on and passed to onResult.
+func (timer *gpuTimer) begin(frame int, onResult func(frame int, elapsed time.Duration)) {
+	if timer.frames[timer.next] >= 0 {
+		onResult(timer.frames[timer.next], timer.read(timer.next))
+	}
+
+	timer.frames[timer.next] = frame
+	gl.BeginQuery(gl.TIME_ELAPSED, timer.queries[timer.next])
+}
+
+func (timer *gpuTimer) end() {
+	gl.EndQuery(gl.TIME_ELAPSED)
+	timer.next = (timer.next + 1) % gpuQueryCount
+}
+
+// collect reads every finished query without stalling on unfinished ones.
+func (timer *gpuTimer) collect(onResult func(frame int, elapsed time.Duration)) {
+	for i := range timer.queries {
+		if timer.frames[i] < 0 {
+			continue
+		}
+
+		var available int32
+		gl.GetQueryObjectiv(timer.queries[i], gl.QUERY_RESULT_AVAILABLE, &available)
+		if available == gl.FALSE {
+			continue
+		}
+
+		onResult(timer.frames[i], timer.read(i))
+	}
+}
+
+func (timer *gpuTimer) read(index int) time.Duration {
+	var elapsed uint64
+	gl.GetQueryObjectui64v(timer.queries[index], gl.QUERY_RESULT, &elapsed)
+	timer.frames[index] = -1
+
+	return time.Duration(elapsed)
+}
+
+func (timer *gpuTimer) delete() {
+	gl.DeleteQueries(gpuQueryCount, &timer.queries[0])
+}
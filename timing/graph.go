@@ -0,0 +1,165 @@
+package timing
+
+import (
+	"time"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/example/shader"
+	"github.com/go-gl/example/utils"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Size of the graph in screen coordinates, scaled by the content scale.
+const graphWidth, graphHeight, graphMargin = 240, 80, 8
+
+// Frame times at the top of the graph, twice the 60Hz budget.
+const graphBudget = time.Second / 60
+const graphRange = 2 * graphBudget
+
+type graph struct {
+	program  shader.Shader
+	vao, vbo uint32
+	vertices []float32
+}
+
+func newGraph() (*graph, error) {
+	program, err := shader.CreateFromSource(graphVertexShader, graphFragmentShader)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &graph{program: program}
+
+	gl.GenVertexArrays(1, &g.vao)
+	gl.BindVertexArray(g.vao)
+
+	gl.GenBuffers(1, &g.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, g.vbo)
+
+	// x, y, r, g, b
+	stride := int32(5 * utils.SizeOfFloat32)
+	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, stride, 0)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, uintptr(2*utils.SizeOfFloat32))
+	gl.EnableVertexAttribArray(1)
+
+	gl.BindVertexArray(0)
+
+	gldebug.Label(gl.PROGRAM, program.ProgramId, "timing graph")
+	gldebug.Label(gl.BUFFER, g.vbo, "timing graph vertices")
+	gldebug.Check("timing.newGraph")
+
+	return g, nil
+}
+
+// DrawGraph draws the total frame time of the last frames as bars in the top
+// left corner of the default framebuffer. Bars are green under the 60Hz
+// budget and red over it.
+func (timer *FrameTimer) DrawGraph(framebufferWidth int, framebufferHeight int, scale float32) error {
+	if timer.graph == nil {
+		g, err := newGraph()
+		if err != nil {
+			return err
+		}
+		timer.graph = g
+	}
+
+	timer.graph.draw(timer.total.Values(), timer.Options.Frames, framebufferWidth, framebufferHeight, scale)
+	return nil
+}
+
+func (g *graph) draw(values []time.Duration, capacity int, framebufferWidth int, framebufferHeight int, scale float32) {
+	// Pixel to normalized device coordinates
+	toX := func(x float32) float32 { return x/float32(framebufferWidth)*2 - 1 }
+	toY := func(y float32) float32 { return 1 - y/float32(framebufferHeight)*2 }
+
+	left := graphMargin * scale
+	top := graphMargin * scale
+	width := graphWidth * scale
+	height := graphHeight * scale
+	bottom := top + height
+
+	g.vertices = g.vertices[:0]
+	quad := func(x0, y0, x1, y1, r, gr, b float32) {
+		g.vertices = append(g.vertices,
+			toX(x0), toY(y0), r, gr, b,
+			toX(x1), toY(y0), r, gr, b,
+			toX(x1), toY(y1), r, gr, b,
+			toX(x0), toY(y0), r, gr, b,
+			toX(x1), toY(y1), r, gr, b,
+			toX(x0), toY(y1), r, gr, b,
+		)
+	}
+
+	quad(left, top, left+width, bottom, 0.1, 0.1, 0.1)
+
+	barWidth := width / float32(capacity)
+	for i, value := range values {
+		fraction := float32(value) / float32(graphRange)
+		if fraction > 1 {
+			fraction = 1
+		}
+
+		x := left + float32(i)*barWidth
+		if value > graphBudget {
+			quad(x, bottom-fraction*height, x+barWidth, bottom, 0.9, 0.2, 0.2)
+		} else {
+			quad(x, bottom-fraction*height, x+barWidth, bottom, 0.2, 0.8, 0.2)
+		}
+	}
+
+	// Budget line
+	budgetY := bottom - height*float32(graphBudget)/float32(graphRange)
+	quad(left, budgetY, left+width, budgetY+scale, 1, 1, 1)
+
+	var previousProgram, previousVAO int32
+	gl.GetIntegerv(gl.CURRENT_PROGRAM, &previousProgram)
+	gl.GetIntegerv(gl.VERTEX_ARRAY_BINDING, &previousVAO)
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+
+	gl.Disable(gl.DEPTH_TEST)
+	g.program.Use()
+	gl.BindVertexArray(g.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, g.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(g.vertices)*utils.SizeOfFloat32, gl.Ptr(g.vertices), gl.STREAM_DRAW)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(g.vertices)/5))
+
+	gl.BindVertexArray(uint32(previousVAO))
+	gl.UseProgram(uint32(previousProgram))
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+
+	gldebug.Check("timing.DrawGraph")
+}
+
+func (g *graph) delete() {
+	gl.DeleteBuffers(1, &g.vbo)
+	gl.DeleteVertexArrays(1, &g.vao)
+	g.program.Delete()
+}
+
+var graphVertexShader = `
+#version 330 core
+layout (location = 0) in vec2 aPos;
+layout (location = 1) in vec3 aColor;
+
+out vec3 color;
+
+void main()
+{
+  gl_Position = vec4(aPos, 0.0, 1.0);
+  color = aColor;
+}
+`
+
+var graphFragmentShader = `
+#version 330 core
+in vec3 color;
+out vec4 FragColor;
+
+void main()
+{
+  FragColor = vec4(color, 1.0);
+}
+`
@@ -0,0 +1,92 @@
+package timing
+
+import (
+	"sort"
+	"time"
+)
+
+type Stats struct {
+	Min time.Duration
+	Avg time.Duration
+	Max time.Duration
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// Series keeps the last N durations added to it in a ring buffer.
+type Series struct {
+	values []time.Duration
+	next   int
+	full   bool
+}
+
+func NewSeries(size int) *Series {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Series{values: make([]time.Duration, size)}
+}
+
+func (series *Series) Add(value time.Duration) {
+	series.values[series.next] = value
+	series.next++
+
+	if series.next == len(series.values) {
+		series.next = 0
+		series.full = true
+	}
+}
+
+func (series *Series) Len() int {
+	if series.full {
+		return len(series.values)
+	}
+
+	return series.next
+}
+
+// Values returns the durations held by the series, oldest first.
+func (series *Series) Values() []time.Duration {
+	if !series.full {
+		return append([]time.Duration(nil), series.values[:series.next]...)
+	}
+
+	values := make([]time.Duration, 0, len(series.values))
+	values = append(values, series.values[series.next:]...)
+	return append(values, series.values[:series.next]...)
+}
+
+func (series *Series) Stats() Stats {
+	values := series.Values()
+	if len(values) == 0 {
+		return Stats{}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	var sum time.Duration
+	for _, value := range values {
+		sum += value
+	}
+
+	return Stats{
+		Min: values[0],
+		Avg: sum / time.Duration(len(values)),
+		Max: values[len(values)-1],
+		P50: percentile(values, 50),
+		P95: percentile(values, 95),
+		P99: percentile(values, 99),
+	}
+}
+
+// percentile uses the nearest-rank method on already sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	core "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// VertexAttribute describes one field of a vertex struct as
+// glVertexAttribPointer wants it.
+type VertexAttribute struct {
+	Name       string
+	Location   uint32
+	Size       int32
+	Type       uint32
+	Normalized bool
+	Offset     uintptr
+}
+
+type VertexLayout struct {
+	Stride     int32
+	Attributes []VertexAttribute
+}
+
+// LayoutOf derives the vertex layout of a struct, vertex may be a struct value,
+// a pointer to one or its reflect.Type. Fields can be scalars or arrays of up to
+// four float32, int8, uint8, int16, uint16, int32 or uint32 values, so
+// mgl32.Vec3 works as well as [3]float32.
+//
+// Fields are configured with a gl tag:
+//
+//	Position mgl32.Vec3 `gl:"location=0"`
+//	Color    [4]uint8   `gl:"location=2,normalized"`
+//	_        [4]byte    `gl:"-"`
+//
+// Fields without a location get the one after the previous field's. Offsets
+// and stride come from the Go struct, padding the compiler inserts included.
+func LayoutOf(vertex interface{}) (VertexLayout, error) {
+	t, ok := vertex.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(vertex)
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return VertexLayout{}, fmt.Errorf("vertex layout: %v is not a struct", t)
+	}
+
+	layout := VertexLayout{Stride: int32(t.Size())}
+	used := map[uint32]string{}
+	var location uint32
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, hasTag := field.Tag.Lookup("gl")
+		if tag == "-" || (field.Name == "_" && !hasTag) {
+			continue
+		}
+
+		attribute := VertexAttribute{
+			Name:     field.Name,
+			Location: location,
+			Offset:   field.Offset,
+		}
+
+		if err := parseVertexTag(tag, &attribute); err != nil {
+			return VertexLayout{}, fmt.Errorf("vertex layout: field %s: %v", field.Name, err)
+		}
+
+		size, glType, err := attributeType(field.Type)
+		if err != nil {
+			return VertexLayout{}, fmt.Errorf("vertex layout: field %s: %v", field.Name, err)
+		}
+		attribute.Size = size
+		attribute.Type = glType
+
+		if attribute.Normalized && glType == gl.FLOAT {
+			return VertexLayout{}, fmt.Errorf("vertex layout: field %s: float attributes can't be normalized", field.Name)
+		}
+
+		if other, ok := used[attribute.Location]; ok {
+			return VertexLayout{}, fmt.Errorf("vertex layout: fields %s and %s share location %d", other, field.Name, attribute.Location)
+		}
+		used[attribute.Location] = field.Name
+
+		layout.Attributes = append(layout.Attributes, attribute)
+		location = attribute.Location + 1
+	}
+
+	if len(layout.Attributes) == 0 {
+		return VertexLayout{}, fmt.Errorf("vertex layout: %v has no attributes", t)
+	}
+
+	return layout, nil
+}
+
+// MustLayoutOf is LayoutOf for layouts known at compile time, it panics on error.
+func MustLayoutOf(vertex interface{}) VertexLayout {
+	layout, err := LayoutOf(vertex)
+	if err != nil {
+		panic(err)
+	}
+
+	return layout
+}
+
+func parseVertexTag(tag string, attribute *VertexAttribute) error {
+	if tag == "" {
+		return nil
+	}
+
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch key {
+		case "location":
+			location, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid location %q", value)
+			}
+			attribute.Location = uint32(location)
+
+		case "normalized":
+			attribute.Normalized = true
+
+		default:
+			return fmt.Errorf("unknown gl tag option %q", key)
+		}
+	}
+
+	return nil
+}
+
+func attributeType(t reflect.Type) (int32, uint32, error) {
+	size := int32(1)
+	if t.Kind() == reflect.Array {
+		size = int32(t.Len())
+		t = t.Elem()
+	}
+
+	if size < 1 || size > 4 {
+		return 0, 0, fmt.Errorf("attributes have 1 to 4 components, not %d", size)
+	}
+
+	switch t.Kind() {
+	case reflect.Float32:
+		return size, gl.FLOAT, nil
+	case reflect.Int8:
+		return size, gl.BYTE, nil
+	case reflect.Uint8:
+		return size, gl.UNSIGNED_BYTE, nil
+	case reflect.Int16:
+		return size, gl.SHORT, nil
+	case reflect.Uint16:
+		return size, gl.UNSIGNED_SHORT, nil
+	case reflect.Int32:
+		return size, gl.INT, nil
+	case reflect.Uint32:
+		return size, gl.UNSIGNED_INT, nil
+	}
+
+	return 0, 0, fmt.Errorf("unsupported attribute type %v", t)
+}
+
+// Apply sets up the attribute pointers of the bound vertex array object to read
+// from the bound ARRAY_BUFFER with this layout. Integer attributes that aren't
+// normalized reach the shader as ints, declare them int, uint or ivecN there.
+func (layout VertexLayout) Apply() {
+	for _, attribute := range layout.Attributes {
+		if attribute.Type != gl.FLOAT && !attribute.Normalized {
+			// Missing from the v2.1 binding, window.Create initializes the core one
+			core.VertexAttribIPointer(attribute.Location, attribute.Size, attribute.Type, layout.Stride, core.PtrOffset(int(attribute.Offset)))
+		} else {
+			gl.VertexAttribPointerWithOffset(attribute.Location, attribute.Size, attribute.Type, attribute.Normalized, layout.Stride, attribute.Offset)
+		}
+		gl.EnableVertexAttribArray(attribute.Location)
+	}
+}
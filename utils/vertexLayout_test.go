@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestLayoutOfMixedTypes(t *testing.T) {
+	type vertex struct {
+		Position [3]float32 `gl:"location=0"`
+		Flags    uint8
+		Normal   [3]int16 `gl:"normalized"`
+		Color    [4]uint8 `gl:"location=5,normalized"`
+		Bone     int32
+		_        [2]byte
+		UV       mgl32.Vec2 `gl:"location=8"`
+	}
+
+	layout, err := LayoutOf(vertex{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := VertexLayout{
+		// Flags is padded to Normal's 2 byte alignment, the blank field to UV's 4
+		Stride: 40,
+		Attributes: []VertexAttribute{
+			{Name: "Position", Location: 0, Size: 3, Type: gl.FLOAT, Offset: 0},
+			{Name: "Flags", Location: 1, Size: 1, Type: gl.UNSIGNED_BYTE, Offset: 12},
+			{Name: "Normal", Location: 2, Size: 3, Type: gl.SHORT, Normalized: true, Offset: 14},
+			{Name: "Color", Location: 5, Size: 4, Type: gl.UNSIGNED_BYTE, Normalized: true, Offset: 20},
+			{Name: "Bone", Location: 6, Size: 1, Type: gl.INT, Offset: 24},
+			{Name: "UV", Location: 8, Size: 2, Type: gl.FLOAT, Offset: 32},
+		},
+	}
+
+	if !reflect.DeepEqual(layout, want) {
+		t.Errorf("LayoutOf = %+v, want %+v", layout, want)
+	}
+}
+
+func TestLayoutOfTrailingPadding(t *testing.T) {
+	type vertex struct {
+		Position [3]float32
+		Index    uint16
+	}
+
+	layout, err := LayoutOf(&vertex{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if layout.Stride != 16 {
+		t.Errorf("stride = %d, want 16", layout.Stride)
+	}
+
+	if got := layout.Attributes[1]; got.Offset != 12 || got.Type != gl.UNSIGNED_SHORT || got.Location != 1 {
+		t.Errorf("Index = %+v, want offset 12, unsigned short at location 1", got)
+	}
+}
+
+func TestLayoutOfType(t *testing.T) {
+	type vertex struct {
+		Position mgl32.Vec3
+	}
+
+	layout, err := LayoutOf(reflect.TypeOf(vertex{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if layout.Stride != 12 || len(layout.Attributes) != 1 {
+		t.Errorf("LayoutOf = %+v, want one attribute with stride 12", layout)
+	}
+}
+
+func TestLayoutOfErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		vertex interface{}
+	}{
+		{"not a struct", 1.0},
+		{"nil", nil},
+		{"no attributes", struct{ _ [4]byte }{}},
+		{"unsupported type", struct{ Weight float64 }{}},
+		{"too many components", struct{ Matrix [16]float32 }{}},
+		{"normalized float", struct {
+			Position [3]float32 `gl:"normalized"`
+		}{}},
+		{"shared location", struct {
+			Position [3]float32 `gl:"location=1"`
+			Normal   [3]float32 `gl:"location=1"`
+		}{}},
+		{"bad location", struct {
+			Position [3]float32 `gl:"location=x"`
+		}{}},
+		{"unknown option", struct {
+			Position [3]float32 `gl:"instanced"`
+		}{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := LayoutOf(test.vertex); err == nil {
+				t.Errorf("LayoutOf succeeded, want an error")
+			}
+		})
+	}
+}
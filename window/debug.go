@@ -0,0 +1,34 @@
+package window
+
+import (
+	"log/slog"
+
+	"github.com/go-gl/example/gldebug"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var debugContext bool
+var debugOptions gldebug.Options
+
+// EnableDebug requests a debug context from Create and routes its messages
+// through gldebug. Must be called before Create.
+func EnableDebug(options gldebug.Options) {
+	debugContext = true
+	debugOptions = options
+}
+
+func debugHint() {
+	if debugContext {
+		glfw.WindowHint(glfw.OpenGLDebugContext, glfw.True)
+	}
+}
+
+func enableDebugOutput() {
+	if !debugContext {
+		return
+	}
+
+	if err := gldebug.Enable(debugOptions); err != nil {
+		slog.Warn("window: " + err.Error())
+	}
+}
@@ -0,0 +1,115 @@
+package window
+
+import (
+	"errors"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+type DisplayMode int
+
+const (
+	Windowed DisplayMode = iota
+	// Borderless covers a whole monitor with an undecorated window, the
+	// monitor keeps its current video mode.
+	Borderless
+	// Fullscreen takes exclusive ownership of a monitor and may switch its
+	// video mode.
+	Fullscreen
+)
+
+var displayMode = Windowed
+var displayMonitor *glfw.Monitor
+
+// Geometry of the window the last time it was in windowed mode, restored by
+// SetWindowed.
+var windowedX, windowedY, windowedWidth, windowedHeight int
+
+func CurrentDisplayMode() DisplayMode {
+	return displayMode
+}
+
+// SetWindowed puts the window back to the position and size it had before it
+// went borderless or fullscreen.
+func SetWindowed() {
+	if current == nil || displayMode == Windowed {
+		return
+	}
+
+	current.SetMonitor(nil, windowedX, windowedY, windowedWidth, windowedHeight, glfw.DontCare)
+	current.SetAttrib(glfw.Decorated, glfw.True)
+
+	displayMode = Windowed
+	displayMonitor = nil
+}
+
+// SetBorderless stretches an undecorated window over the monitor. It fails
+// for a nil or disconnected monitor.
+func SetBorderless(monitor *Monitor) error {
+	handle, err := connectedHandle(monitor)
+	if err != nil {
+		return err
+	}
+
+	mode := handle.GetVideoMode()
+	if mode == nil {
+		return errors.New("window: can't read the monitor's video mode")
+	}
+	x, y := handle.GetPos()
+
+	saveWindowedGeometry()
+
+	current.SetAttrib(glfw.Decorated, glfw.False)
+	current.SetMonitor(nil, x, y, mode.Width, mode.Height, glfw.DontCare)
+
+	displayMode = Borderless
+	displayMonitor = handle
+	return nil
+}
+
+// SetFullscreen makes the window exclusive fullscreen on the monitor using
+// mode, which should be one of the monitor's VideoModes. The closest supported
+// mode is picked otherwise. It fails for a nil or disconnected monitor.
+func SetFullscreen(monitor *Monitor, mode VideoMode) error {
+	handle, err := connectedHandle(monitor)
+	if err != nil {
+		return err
+	}
+
+	saveWindowedGeometry()
+
+	current.SetAttrib(glfw.Decorated, glfw.True)
+	current.SetMonitor(handle, 0, 0, mode.Width, mode.Height, mode.RefreshRate)
+
+	displayMode = Fullscreen
+	displayMonitor = handle
+	return nil
+}
+
+// connectedHandle returns the GLFW monitor of monitor if it's still
+// connected, GLFW frees disconnected ones.
+func connectedHandle(monitor *Monitor) (*glfw.Monitor, error) {
+	if current == nil {
+		return nil, errors.New("window: no window is open")
+	}
+	if monitor == nil || monitor.handle == nil {
+		return nil, errors.New("window: no monitor")
+	}
+
+	for _, handle := range glfw.GetMonitors() {
+		if handle == monitor.handle {
+			return handle, nil
+		}
+	}
+
+	return nil, errors.New("window: monitor " + monitor.Name + " is disconnected")
+}
+
+func saveWindowedGeometry() {
+	if displayMode != Windowed {
+		return
+	}
+
+	windowedX, windowedY = current.GetPos()
+	windowedWidth, windowedHeight = current.GetSize()
+}
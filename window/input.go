@@ -0,0 +1,101 @@
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Cursor and scroll state. Callbacks accumulate into the pending values
+// between frames, beginInput turns them into the ones the frame reads so they
+// stay the same during the whole update.
+var (
+	cursorX, cursorY           float64
+	frameCursorX, frameCursorY float64
+	cursorDeltaX, cursorDeltaY float64
+	pendingScrollX             float64
+	pendingScrollY             float64
+	scrollX, scrollY           float64
+	// skipCursorDelta is set when the cursor jumps without moving, the first
+	// frame and when it's captured or released.
+	skipCursorDelta = true
+	cursorCaptured  bool
+)
+
+// KeyDown reports whether key is held.
+func KeyDown(key glfw.Key) bool {
+	return current != nil && current.GetKey(key) == glfw.Press
+}
+
+// MouseButtonDown reports whether button is held.
+func MouseButtonDown(button glfw.MouseButton) bool {
+	return current != nil && current.GetMouseButton(button) == glfw.Press
+}
+
+// Cursor returns the cursor position in screen coordinates from the top left
+// of the window.
+func Cursor() (x float64, y float64) {
+	return frameCursorX, frameCursorY
+}
+
+// CursorDelta returns how far the cursor moved since the previous frame, in
+// screen coordinates.
+func CursorDelta() (x float64, y float64) {
+	return cursorDeltaX, cursorDeltaY
+}
+
+// Scroll returns how much the mouse wheel or touchpad scrolled since the
+// previous frame, in steps, positive y going up.
+func Scroll() (x float64, y float64) {
+	return scrollX, scrollY
+}
+
+// SetCursorCaptured hides the cursor and locks it to the window, or releases
+// it. Captured, the cursor keeps moving past the window edges, for looking
+// around with the mouse.
+func SetCursorCaptured(captured bool) {
+	if current == nil || captured == cursorCaptured {
+		return
+	}
+
+	mode := glfw.CursorNormal
+	if captured {
+		mode = glfw.CursorDisabled
+	}
+	current.SetInputMode(glfw.CursorMode, mode)
+
+	if captured && glfw.RawMouseMotionSupported() {
+		current.SetInputMode(glfw.RawMouseMotion, glfw.True)
+	}
+
+	cursorCaptured = captured
+	skipCursorDelta = true
+}
+
+func CursorCaptured() bool {
+	return cursorCaptured
+}
+
+func trackInput(window *glfw.Window) {
+	cursorX, cursorY = window.GetCursorPos()
+
+	window.SetCursorPosCallback(func(_ *glfw.Window, x float64, y float64) {
+		cursorX, cursorY = x, y
+	})
+
+	window.SetScrollCallback(func(_ *glfw.Window, x float64, y float64) {
+		pendingScrollX += x
+		pendingScrollY += y
+	})
+}
+
+func beginInput() {
+	if skipCursorDelta {
+		cursorDeltaX, cursorDeltaY = 0, 0
+		skipCursorDelta = false
+	} else {
+		cursorDeltaX, cursorDeltaY = cursorX-frameCursorX, cursorY-frameCursorY
+	}
+	frameCursorX, frameCursorY = cursorX, cursorY
+
+	scrollX, scrollY = pendingScrollX, pendingScrollY
+	pendingScrollX, pendingScrollY = 0, 0
+}
@@ -0,0 +1,223 @@
+package window
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Work queued by other goroutines for the main thread, the only one allowed
+// to make GL calls.
+var (
+	tasksMutex sync.Mutex
+	tasks      []*task
+	taskBudget = 4 * time.Millisecond
+	// wake wakes Wait up when a task is queued.
+	wake = make(chan struct{}, 1)
+	// closed is set once Create returns, nothing drains the queue until the
+	// next Create.
+	closed bool
+)
+
+type task struct {
+	run func()
+	// done is closed after run returns or when the task is dropped, for Do
+	// to wait on. nil for DoAsync.
+	done chan struct{}
+	ran  bool
+	// dropped is called instead of run when the window closes first.
+	dropped func()
+}
+
+// mainGoroutine is the goroutine running package initialization, the one
+// init locks to the main thread.
+var mainGoroutine = goroutineID()
+
+// goroutineID parses the current goroutine's ID from its stack trace header,
+// "goroutine 1 [running]:".
+func goroutineID() uint64 {
+	var buffer [64]byte
+	header := string(buffer[:runtime.Stack(buffer[:], false)])
+
+	fields := strings.Fields(strings.TrimPrefix(header, "goroutine "))
+	if len(fields) == 0 {
+		return 0
+	}
+
+	id, _ := strconv.ParseUint(fields[0], 10, 64)
+	return id
+}
+
+// IsMainThread reports whether the caller runs on the main thread, where GL
+// calls can be made.
+func IsMainThread() bool {
+	return goroutineID() == mainGoroutine
+}
+
+// Do runs run on the main thread and waits for it to finish. Called from the
+// main thread, run is called right away since waiting for the next frame
+// would deadlock. It panics if the window closes before run is called.
+func Do(run func()) {
+	if IsMainThread() {
+		run()
+		return
+	}
+
+	queued := &task{run: run, done: make(chan struct{})}
+	enqueue(queued)
+
+	<-queued.done
+	if !queued.ran {
+		panic("window: closed before the task ran")
+	}
+}
+
+// DoAsync queues run to be called on the main thread before a coming frame's
+// update and returns immediately. Tasks run in the order they were queued,
+// whichever goroutine queued them, the main thread included. Tasks queued
+// once the window is closed are dropped.
+func DoAsync(run func()) {
+	enqueue(&task{run: run})
+}
+
+// DoAsyncOr is DoAsync calling dropped when run is dropped, so whoever waits
+// on its result can be told. dropped runs on the goroutine queueing the task
+// or closing the window and mustn't make GL calls.
+func DoAsyncOr(run func(), dropped func()) {
+	enqueue(&task{run: run, dropped: dropped})
+}
+
+// RunOnMainThread is DoAsync under its former name.
+//
+// Deprecated: use DoAsync, or Do to wait for the result.
+func RunOnMainThread(task func()) {
+	DoAsync(task)
+}
+
+func enqueue(next *task) {
+	tasksMutex.Lock()
+
+	if closed {
+		tasksMutex.Unlock()
+		next.drop()
+		return
+	}
+
+	tasks = append(tasks, next)
+	tasksMutex.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func (pending *task) drop() {
+	if pending.done != nil {
+		close(pending.done)
+	}
+
+	if pending.dropped != nil {
+		pending.dropped()
+	}
+}
+
+// SetTaskBudget limits how long queued tasks may run each frame, 4ms by
+// default. At least one task runs every frame whatever the budget, the
+// others wait for the next frames so a burst of work doesn't stall one.
+func SetTaskBudget(budget time.Duration) {
+	tasksMutex.Lock()
+	taskBudget = budget
+	tasksMutex.Unlock()
+}
+
+func runTasks() {
+	start := time.Now()
+
+	for ran := 0; ; ran++ {
+		tasksMutex.Lock()
+		budget := taskBudget
+		tasksMutex.Unlock()
+
+		if ran > 0 && time.Since(start) >= budget {
+			return
+		}
+
+		if !runTask() {
+			return
+		}
+	}
+}
+
+// runTask runs the oldest queued task, it returns false when there's none.
+func runTask() bool {
+	tasksMutex.Lock()
+	if len(tasks) == 0 {
+		tasksMutex.Unlock()
+		return false
+	}
+
+	next := tasks[0]
+	tasks[0] = nil
+	tasks = tasks[1:]
+	tasksMutex.Unlock()
+
+	next.run()
+	next.ran = true
+	if next.done != nil {
+		close(next.done)
+	}
+
+	return true
+}
+
+// Wait blocks until done is closed. On the main thread it runs queued tasks
+// meanwhile, ignoring the budget, so waiting on work that itself needs the
+// main thread, like another goroutine's Do, doesn't deadlock.
+func Wait(done <-chan struct{}) {
+	if !IsMainThread() {
+		<-done
+		return
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if runTask() {
+			continue
+		}
+
+		select {
+		case <-done:
+			return
+		case <-wake:
+		}
+	}
+}
+
+// openTasks lets tasks be queued again for a new window.
+func openTasks() {
+	tasksMutex.Lock()
+	closed = false
+	tasksMutex.Unlock()
+}
+
+// closeTasks drops what's left in the queue, the GL context is gone.
+func closeTasks() {
+	tasksMutex.Lock()
+	closed = true
+	dropped := tasks
+	tasks = nil
+	tasksMutex.Unlock()
+
+	// Outside the lock, dropped callbacks may queue more work
+	for _, queued := range dropped {
+		queued.drop()
+	}
+}
@@ -0,0 +1,127 @@
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+type VideoMode struct {
+	Width       int
+	Height      int
+	RedBits     int
+	GreenBits   int
+	BlueBits    int
+	RefreshRate int
+}
+
+// Monitor is a snapshot of a connected display. The fields are read when the
+// monitor is looked up and don't change afterwards, call Monitors again to
+// pick up a new video mode or content scale.
+type Monitor struct {
+	Name string
+
+	// Physical size of the display area in millimetres, zero when the
+	// platform can't tell.
+	PhysicalWidth  int
+	PhysicalHeight int
+
+	ContentScaleX float32
+	ContentScaleY float32
+
+	// Position of the monitor on the virtual desktop in screen coordinates.
+	X int
+	Y int
+
+	CurrentMode VideoMode
+	VideoModes  []VideoMode
+
+	handle *glfw.Monitor
+}
+
+var onMonitorConnected, onMonitorDisconnected func(monitor *Monitor)
+
+// Monitors returns every connected monitor, the primary one first.
+// Must be called after Create, from the start or update callbacks.
+func Monitors() []*Monitor {
+	handles := glfw.GetMonitors()
+
+	monitors := make([]*Monitor, 0, len(handles))
+	for _, handle := range handles {
+		monitors = append(monitors, newMonitor(handle))
+	}
+
+	return monitors
+}
+
+func PrimaryMonitor() *Monitor {
+	handle := glfw.GetPrimaryMonitor()
+	if handle == nil {
+		return nil
+	}
+
+	return newMonitor(handle)
+}
+
+// OnMonitorConnected sets the function called when a monitor is plugged in.
+func OnMonitorConnected(callback func(monitor *Monitor)) {
+	onMonitorConnected = callback
+}
+
+// OnMonitorDisconnected sets the function called when a monitor is unplugged.
+// Only the monitor's fields are valid, SetBorderless and SetFullscreen return
+// an error for it. If the window was fullscreen on it, it is put back in
+// windowed mode before the callback runs.
+func OnMonitorDisconnected(callback func(monitor *Monitor)) {
+	onMonitorDisconnected = callback
+}
+
+func onMonitorEvent(handle *glfw.Monitor, event glfw.PeripheralEvent) {
+	switch event {
+	case glfw.Connected:
+		if onMonitorConnected != nil {
+			onMonitorConnected(newMonitor(handle))
+		}
+
+	case glfw.Disconnected:
+		monitor := newMonitor(handle)
+
+		if displayMode != Windowed && displayMonitor == handle {
+			SetWindowed()
+		}
+
+		if onMonitorDisconnected != nil {
+			onMonitorDisconnected(monitor)
+		}
+	}
+}
+
+func newMonitor(handle *glfw.Monitor) *Monitor {
+	monitor := &Monitor{
+		Name:   handle.GetName(),
+		handle: handle,
+	}
+
+	monitor.PhysicalWidth, monitor.PhysicalHeight = handle.GetPhysicalSize()
+	monitor.ContentScaleX, monitor.ContentScaleY = handle.GetContentScale()
+	monitor.X, monitor.Y = handle.GetPos()
+
+	if mode := handle.GetVideoMode(); mode != nil {
+		monitor.CurrentMode = newVideoMode(mode)
+	}
+
+	for _, mode := range handle.GetVideoModes() {
+		monitor.VideoModes = append(monitor.VideoModes, newVideoMode(mode))
+	}
+
+	return monitor
+}
+
+func newVideoMode(mode *glfw.VidMode) VideoMode {
+	return VideoMode{
+		Width:       mode.Width,
+		Height:      mode.Height,
+		RedBits:     mode.RedBits,
+		GreenBits:   mode.GreenBits,
+		BlueBits:    mode.BlueBits,
+		RefreshRate: mode.RefreshRate,
+	}
+}
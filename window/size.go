@@ -0,0 +1,88 @@
+package window
+
+import (
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// On HiDPI displays the window size is in screen coordinates while the
+// framebuffer is in pixels, so the two differ by the content scale. GL only
+// cares about the framebuffer.
+var windowWidth, windowHeight int
+var framebufferWidth, framebufferHeight int
+var contentScaleX, contentScaleY float32 = 1, 1
+
+// Pointers so callbacks can be told apart when removed.
+var framebufferResizeCallbacks []*func(width int, height int)
+
+// Size returns the window size in screen coordinates.
+func Size() (width int, height int) {
+	return windowWidth, windowHeight
+}
+
+// FramebufferSize returns the size of the default framebuffer in pixels.
+func FramebufferSize() (width int, height int) {
+	return framebufferWidth, framebufferHeight
+}
+
+func ContentScale() (x float32, y float32) {
+	return contentScaleX, contentScaleY
+}
+
+// AspectRatio is the framebuffer width divided by its height, the value
+// projection matrices should be built with.
+func AspectRatio() float32 {
+	if framebufferHeight == 0 {
+		return 1
+	}
+
+	return float32(framebufferWidth) / float32(framebufferHeight)
+}
+
+// OnFramebufferResize adds a function called after the framebuffer changes
+// size, the viewport has already been updated by then. Callbacks aren't called
+// while the window is minimized. The returned function removes the callback.
+func OnFramebufferResize(callback func(width int, height int)) (remove func()) {
+	added := &callback
+	framebufferResizeCallbacks = append(framebufferResizeCallbacks, added)
+
+	return func() {
+		for i, registered := range framebufferResizeCallbacks {
+			if registered == added {
+				framebufferResizeCallbacks = append(framebufferResizeCallbacks[:i:i], framebufferResizeCallbacks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func trackSize(window *glfw.Window) {
+	windowWidth, windowHeight = window.GetSize()
+	framebufferWidth, framebufferHeight = window.GetFramebufferSize()
+	contentScaleX, contentScaleY = window.GetContentScale()
+
+	gl.Viewport(0, 0, int32(framebufferWidth), int32(framebufferHeight))
+
+	window.SetSizeCallback(func(_ *glfw.Window, width int, height int) {
+		windowWidth, windowHeight = width, height
+	})
+
+	window.SetFramebufferSizeCallback(func(_ *glfw.Window, width int, height int) {
+		// Minimizing reports a 0x0 framebuffer, keep the last real size so
+		// aspect ratios stay valid.
+		if width == 0 || height == 0 {
+			return
+		}
+
+		framebufferWidth, framebufferHeight = width, height
+		gl.Viewport(0, 0, int32(width), int32(height))
+
+		for _, callback := range framebufferResizeCallbacks {
+			(*callback)(width, height)
+		}
+	})
+
+	window.SetContentScaleCallback(func(_ *glfw.Window, x float32, y float32) {
+		contentScaleX, contentScaleY = x, y
+	})
+}
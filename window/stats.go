@@ -0,0 +1,61 @@
+package window
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-gl/example/timing"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// How often the title is rewritten, setting it every frame is slow on some
+// platforms and unreadable anyway.
+const titleInterval = 500 * time.Millisecond
+
+var frameTimer *timing.FrameTimer
+var title string
+var titleUpdated time.Time
+
+// SetFrameTimer makes the window loop time every frame with timer, showing its
+// stats in the title or as a graph depending on the timer's options. Pass nil
+// to stop timing.
+func SetFrameTimer(timer *timing.FrameTimer) {
+	if frameTimer != nil && frameTimer.Options.Title && current != nil {
+		current.SetTitle(title)
+	}
+
+	frameTimer = timer
+}
+
+func beginFrame() {
+	if frameTimer != nil {
+		frameTimer.BeginFrame()
+	}
+}
+
+func endUpdate(window *glfw.Window) {
+	if frameTimer == nil {
+		return
+	}
+
+	// Stop the timers first, the overlay isn't part of the frame it shows
+	frameTimer.EndUpdate()
+
+	if frameTimer.Options.Graph {
+		if err := frameTimer.DrawGraph(framebufferWidth, framebufferHeight, contentScaleY); err != nil {
+			slog.Error("window: failed to draw the frame graph, turning it off", "error", err)
+			frameTimer.Options.Graph = false
+		}
+	}
+
+	if frameTimer.Options.Title && time.Since(titleUpdated) >= titleInterval {
+		window.SetTitle(title + " | " + frameTimer.String())
+		titleUpdated = time.Now()
+	}
+}
+
+func endSwap() {
+	if frameTimer != nil {
+		frameTimer.EndSwap()
+	}
+}
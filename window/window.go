@@ -1,13 +1,20 @@
 package window
 
 import (
+	"log"
 	"runtime"
-  "log"
 
 	"github.com/go-gl/gl/v2.1/gl"
+	core "github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
+// current is the window opened by Create, nil before it's opened.
+var current *glfw.Window
+
+// frame counts the frames begun, see Frame.
+var frame uint64
+
 func init() {
 	// GLFW event handling must run on the main OS thread
 	runtime.LockOSThread()
@@ -19,11 +26,13 @@ func Create(width int, height int, name string, onStart func(), onUpdate func())
 	}
 	defer glfw.Terminate()
 
-	glfw.WindowHint(glfw.Resizable, glfw.False)
-  glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.Resizable, glfw.True)
+	glfw.WindowHint(glfw.ScaleToMonitor, glfw.True)
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
 	glfw.WindowHint(glfw.ContextVersionMinor, 3)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	debugHint()
 
 	window, err := glfw.CreateWindow(width, height, name, nil, nil)
 	if err != nil {
@@ -34,14 +43,46 @@ func Create(width int, height int, name string, onStart func(), onUpdate func())
 	if err := gl.Init(); err != nil {
 		panic(err)
 	}
+	// The few 3.x entry points the v2.1 binding lacks are called through the
+	// core binding, it loads its own function pointers.
+	if err := core.Init(); err != nil {
+		panic(err)
+	}
+
+	enableDebugOutput()
 
-  onStart();
+	current = window
+	title = name
+	openTasks()
+	defer func() { current = nil }()
+	defer closeTasks()
 
-  for !window.ShouldClose() {
-    onUpdate()
-    window.SwapBuffers()
-    glfw.PollEvents()
-  }
+	trackSize(window)
+	trackInput(window)
+
+	glfw.SetMonitorCallback(onMonitorEvent)
+
+	// A new context, onStart counts as a frame of its own
+	frame++
+	onStart()
+
+	for !window.ShouldClose() {
+		frame++
+		beginFrame()
+		beginInput()
+		runTasks()
+		onUpdate()
+		endUpdate(window)
+		window.SwapBuffers()
+		endSwap()
+		glfw.PollEvents()
+	}
 
 }
 
+// Frame returns a number identifying the frame being drawn, onStart counting
+// as one. Code caching GL state can tell from it that a new frame began and
+// whatever ran in between, like the frame graph, may have changed the state.
+func Frame() uint64 {
+	return frame
+}